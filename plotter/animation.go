@@ -0,0 +1,404 @@
+package plotter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	imgdraw "image/draw"
+	"image/gif"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// animation of a plot changed by a callback before each frame, or of a sequence of plots,
+// saved as an animated GIF, an APNG or a directory of numbered frames
+type Animation struct {
+	plot    Plot
+	update  func(frame int, p PlotterInterface)
+	plots   []Plot
+	frames  int
+	options animationOptions
+	err     error
+}
+
+type animationOptions struct {
+	fps   float64
+	loops int
+	skip  int
+}
+
+// frames shown per second
+func WithFPS(fps float64) func(*animationOptions) {
+	return func(ao *animationOptions) {
+		ao.fps = fps
+	}
+}
+
+// number of times the animation is played, zero to play it forever
+func WithLoops(loops int) func(*animationOptions) {
+	return func(ao *animationOptions) {
+		ao.loops = loops
+	}
+}
+
+// draw only every n-th frame, the update is still called for every frame
+func WithFrameSkip(n int) func(*animationOptions) {
+	return func(ao *animationOptions) {
+		ao.skip = n
+	}
+}
+
+// animation of frames frames of the plot, update changes the plot before each frame is drawn
+func NewAnimation(p Plot, frames int, update func(frame int, p PlotterInterface), options ...func(*animationOptions)) *Animation {
+	a := &Animation{plot: p, update: update, frames: frames}
+	a.setOptions(options...)
+	if frames < 1 {
+		a.setErr(fmt.Errorf("plotter: invalid number of frames %d", frames))
+	}
+	return a
+}
+
+// animation with one plot for each frame
+func NewAnimationOf(plots []Plot, options ...func(*animationOptions)) *Animation {
+	a := &Animation{plots: plots, frames: len(plots)}
+	a.setOptions(options...)
+	if len(plots) == 0 {
+		a.setErr(ErrEmptyData)
+	}
+	return a
+}
+
+func (a *Animation) setOptions(options ...func(*animationOptions)) {
+	// default options
+	a.options = animationOptions{
+		fps:  10,
+		skip: 1,
+	}
+
+	// apply additional options
+	for _, option := range options {
+		option(&a.options)
+	}
+
+	switch {
+	case a.options.fps <= 0 || math.IsInf(a.options.fps, 0) || math.IsNaN(a.options.fps):
+		a.setErr(fmt.Errorf("plotter: invalid frame rate %g", a.options.fps))
+	case a.options.loops < 0:
+		a.setErr(fmt.Errorf("plotter: invalid loop count %d", a.options.loops))
+	case a.options.skip < 1:
+		a.setErr(fmt.Errorf("plotter: invalid frame skip %d", a.options.skip))
+	}
+}
+
+// record the first error found while building the animation
+func (a *Animation) setErr(err error) {
+	if a.err == nil {
+		a.err = err
+	}
+}
+
+// first error found while building the animation
+func (a *Animation) Err() error {
+	return a.err
+}
+
+// number of frames drawn
+func (a *Animation) count() int {
+	return (a.frames + a.options.skip - 1) / a.options.skip
+}
+
+// call draw with each drawn frame, numbered from zero, and its plot
+func (a *Animation) each(draw func(n int, p Plot) error) error {
+	if a.err != nil {
+		return a.err
+	}
+
+	for i, n := 0, 0; i < a.frames; i++ {
+		p := a.plot
+		if a.plots != nil {
+			p = a.plots[i]
+		} else if a.update != nil {
+			a.update(i, p)
+		}
+		if i%a.options.skip != 0 {
+			continue
+		}
+
+		if p == nil {
+			return fmt.Errorf("plotter: nil plot in frame %d", i)
+		}
+		if err := p.Err(); err != nil {
+			return err
+		}
+		if err := draw(n, p); err != nil {
+			return err
+		}
+		n++
+	}
+	return nil
+}
+
+// raster image of each drawn frame, all of the size of the first one
+func (a *Animation) images(draw func(n int, img image.Image) error) error {
+	var size image.Point
+	return a.each(func(n int, p Plot) error {
+		img := p.Image()
+		if img == nil {
+			return fmt.Errorf("plotter: frame %d not drawn", n)
+		}
+		if n == 0 {
+			size = img.Bounds().Size()
+		} else if s := img.Bounds().Size(); s != size {
+			return fmt.Errorf("plotter: frame %d is %dx%d px, the first frame is %dx%d px", n, s.X, s.Y, size.X, size.Y)
+		}
+		return draw(n, img)
+	})
+}
+
+// save the animation as an animated GIF or, for the png and apng extensions, as an APNG
+func (a *Animation) Save(file string) error {
+	if a.err != nil {
+		return a.err
+	}
+
+	var write func(io.Writer) error
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".gif":
+		write = a.WriteGIF
+	case ".png", ".apng":
+		write = a.WriteAPNG
+	default:
+		return fmt.Errorf("plotter: unsupported animation extension %q", ext)
+	}
+
+	// save the animation to a file
+	w, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := write(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// save each drawn frame to a numbered file of the directory, in the given format
+func (a *Animation) SaveFrames(dir string, format formatType) error {
+	if a.err != nil {
+		return a.err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	return a.each(func(n int, p Plot) error {
+		w, err := os.Create(filepath.Join(dir, fmt.Sprintf("frame%04d.%s", n, format)))
+		if err != nil {
+			return err
+		}
+
+		if _, err := p.WriteTo(w, format); err != nil {
+			w.Close()
+			return err
+		}
+		return w.Close()
+	})
+}
+
+// write the animation as an animated GIF, with a palette of the most used colors of each frame
+func (a *Animation) WriteGIF(w io.Writer) error {
+	// loop count of the GIF, the number of repeats after the first play,
+	// 0 to play forever and -1 to play once
+	g := &gif.GIF{}
+	switch {
+	case a.options.loops == 1:
+		g.LoopCount = -1
+	case a.options.loops > 1:
+		g.LoopCount = a.options.loops - 1
+	}
+	delay := int(math.Max(1, math.Round(100/a.options.fps)))
+
+	err := a.images(func(n int, img image.Image) error {
+		g.Image = append(g.Image, quantize(img))
+		g.Delay = append(g.Delay, delay)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return gif.EncodeAll(w, g)
+}
+
+// frame with a palette of at most 256 colors, the averages of the most used
+// colors of the image with 4 bits for each channel
+func quantize(img image.Image) *image.Paletted {
+	type bin struct {
+		key        int
+		r, g, b, n int
+	}
+	bins := make(map[int]*bin)
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			key := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+			b := bins[key]
+			if b == nil {
+				b = &bin{key: key}
+				bins[key] = b
+			}
+			b.r, b.g, b.b, b.n = b.r+int(c.R), b.g+int(c.G), b.b+int(c.B), b.n+1
+		}
+	}
+
+	sorted := make([]*bin, 0, len(bins))
+	for _, b := range bins {
+		sorted = append(sorted, b)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].n != sorted[j].n {
+			return sorted[i].n > sorted[j].n
+		}
+		return sorted[i].key < sorted[j].key
+	})
+	if len(sorted) > 256 {
+		sorted = sorted[:256]
+	}
+
+	palette := make(color.Palette, len(sorted))
+	for i, b := range sorted {
+		palette[i] = color.RGBA{uint8(b.r / b.n), uint8(b.g / b.n), uint8(b.b / b.n), 255}
+	}
+	frame := image.NewPaletted(bounds, palette)
+	imgdraw.Draw(frame, bounds, img, bounds.Min, imgdraw.Src)
+	return frame
+}
+
+// write the animation as an animated PNG, shown as its first frame by viewers without APNG support
+func (a *Animation) WriteAPNG(w io.Writer) error {
+	aw := &apngWriter{w: w, frames: a.count(), loops: a.options.loops}
+	delay := uint16(math.Min(math.MaxUint16, math.Round(1000/a.options.fps)))
+
+	err := a.images(func(n int, img image.Image) error {
+		return aw.frame(img, delay)
+	})
+	if err != nil {
+		return err
+	}
+	return aw.chunk("IEND", nil)
+}
+
+// writer of the chunks of an animated PNG, with 8-bit RGBA frames
+type apngWriter struct {
+	w             io.Writer
+	frames, loops int
+	seq           uint32 // sequence number of the next fcTL or fdAT chunk
+	written       int    // frames written
+}
+
+// write a chunk with its length and checksum
+func (aw *apngWriter) chunk(name string, data []byte) error {
+	head := make([]byte, 8)
+	binary.BigEndian.PutUint32(head[:4], uint32(len(data)))
+	copy(head[4:], name)
+	crc := crc32.NewIEEE()
+	crc.Write(head[4:])
+	crc.Write(data)
+	sum := make([]byte, 4)
+	binary.BigEndian.PutUint32(sum, crc.Sum32())
+
+	for _, b := range [][]byte{head, data, sum} {
+		if _, err := aw.w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write a frame shown for delay milliseconds, after the header for the first frame
+func (aw *apngWriter) frame(img image.Image, delay uint16) error {
+	size := img.Bounds().Size()
+	if aw.written == 0 {
+		if _, err := io.WriteString(aw.w, "\x89PNG\r\n\x1a\n"); err != nil {
+			return err
+		}
+
+		// 8-bit RGBA without interlacing
+		ihdr := make([]byte, 13)
+		binary.BigEndian.PutUint32(ihdr[0:], uint32(size.X))
+		binary.BigEndian.PutUint32(ihdr[4:], uint32(size.Y))
+		ihdr[8], ihdr[9] = 8, 6
+		if err := aw.chunk("IHDR", ihdr); err != nil {
+			return err
+		}
+
+		actl := make([]byte, 8)
+		binary.BigEndian.PutUint32(actl[0:], uint32(aw.frames))
+		// number of plays, 0 to play forever as the loops of the animation
+		binary.BigEndian.PutUint32(actl[4:], uint32(aw.loops))
+		if err := aw.chunk("acTL", actl); err != nil {
+			return err
+		}
+	}
+
+	// frame over the whole image, replacing the previous one
+	fctl := make([]byte, 26)
+	binary.BigEndian.PutUint32(fctl[0:], aw.seq)
+	binary.BigEndian.PutUint32(fctl[4:], uint32(size.X))
+	binary.BigEndian.PutUint32(fctl[8:], uint32(size.Y))
+	binary.BigEndian.PutUint16(fctl[20:], delay)
+	binary.BigEndian.PutUint16(fctl[22:], 1000)
+	if err := aw.chunk("fcTL", fctl); err != nil {
+		return err
+	}
+	aw.seq++
+
+	data, err := compressRows(img)
+	if err != nil {
+		return err
+	}
+	if aw.written == 0 {
+		err = aw.chunk("IDAT", data)
+	} else {
+		fdat := make([]byte, 4, 4+len(data))
+		binary.BigEndian.PutUint32(fdat, aw.seq)
+		err = aw.chunk("fdAT", append(fdat, data...))
+		aw.seq++
+	}
+	aw.written++
+	return err
+}
+
+// zlib compressed rows of the image as 8-bit RGBA, without filtering
+func compressRows(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	nrgba := image.NewNRGBA(image.Rectangle{Max: bounds.Size()})
+	imgdraw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, imgdraw.Src)
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	width := 4 * nrgba.Rect.Dx()
+	for y := 0; y < nrgba.Rect.Dy(); y++ {
+		row := nrgba.Pix[y*nrgba.Stride : y*nrgba.Stride+width]
+		if _, err := zw.Write([]byte{0}); err != nil {
+			return nil, err
+		}
+		if _, err := zw.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,470 @@
+package plotter
+
+import (
+	"math"
+
+	"github.com/mazznoer/colorgrad"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// handle to a plotted series, to change it after it was plotted
+type Artist interface {
+	SetLabel(label string)
+	SetVisible(visible bool)
+	Visible() bool
+	Remove()
+}
+
+// handle to a line plotted by Plot or LivePlot, safe to use from
+// other goroutines while the figure is shown
+type Line interface {
+	Artist
+	SetData(x, y []float64)
+	Data() (x, y []float64)
+	Append(x, y float64)
+	Stream(points <-chan [2]float64)
+	SetColor(color colorType)
+	SetLineWidth(width float64)
+	SetLineStyle(style lineStyleType)
+}
+
+// handle to the points plotted by Scatter
+type Collection interface {
+	Artist
+	SetData(x, y, z []float64)
+	SetColor(color colorType)
+	SetMarkerSize(size float64)
+}
+
+// handle to the levels plotted by Contour and ContourF
+type ContourSet interface {
+	Artist
+	SetData(x, y, z *mat.Dense)
+}
+
+// handle to the image plotted by ImShow
+type AxesImage interface {
+	Artist
+	SetData(x []*mat.Dense)
+}
+
+// series added by one call to a plotting method, with its plotters
+// and the thumbnails of its legend entry
+type series struct {
+	label    string
+	thumbs   []plot.Thumbnailer
+	plotters []plot.Plotter
+	hidden   bool
+	mark     func(c vg.Canvas, legend bool) (end func()) // marks the drawing of the series for SaveHTML
+}
+
+// add the plotters of a series to the plot, named by its label in the legend
+func (plt *plotParameters) add(label string, thumbs []plot.Thumbnailer, plotters ...plot.Plotter) *series {
+	s := &series{label: label, thumbs: thumbs, plotters: plotters}
+	plt.series = append(plt.series, s)
+	plt.plot.Add(plotters...)
+	return s
+}
+
+// plotters of the shown series, in the order they are drawn
+func (plt *plotParameters) shown() []plot.Plotter {
+	var plotters []plot.Plotter
+	for _, s := range plt.series {
+		if !s.hidden {
+			plotters = append(plotters, s.plotters...)
+		}
+	}
+	return plotters
+}
+
+// plotters of the shown series as they are drawn, inside the marks of their series
+func (plt *plotParameters) drawn() []plot.Plotter {
+	var plotters []plot.Plotter
+	for _, s := range plt.series {
+		if s.hidden {
+			continue
+		}
+		for _, p := range s.plotters {
+			if s.mark != nil {
+				p = markedPlotter{Plotter: p, mark: s.mark}
+			}
+			plotters = append(plotters, p)
+		}
+	}
+	return plotters
+}
+
+// plotter drawn inside the mark of its series, with the data range and glyph boxes of the plotter
+type markedPlotter struct {
+	plot.Plotter
+	mark func(c vg.Canvas, legend bool) (end func())
+}
+
+func (m markedPlotter) Plot(c draw.Canvas, p *plot.Plot) {
+	defer m.mark(c.Canvas, false)()
+	m.Plotter.Plot(c, p)
+}
+
+func (m markedPlotter) DataRange() (xmin, xmax, ymin, ymax float64) {
+	if dr, ok := m.Plotter.(plot.DataRanger); ok {
+		return dr.DataRange()
+	}
+	return math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+}
+
+func (m markedPlotter) GlyphBoxes(p *plot.Plot) []plot.GlyphBox {
+	if gb, ok := m.Plotter.(plot.GlyphBoxer); ok {
+		return gb.GlyphBoxes(p)
+	}
+	return nil
+}
+
+// new plot with the settings of the current one and the plotters of the shown series,
+// so that the axis ranges fit their data again
+func (plt *plotParameters) refit() {
+	plt.plot = plt.replot(true)
+	plt.stale = false
+}
+
+// new plot with the settings of the current one and the plotters of the shown series,
+// with the axis ranges fitted to their data or kept as they are
+func (plt *plotParameters) replot(fit bool) *plot.Plot {
+	p := plot.New()
+	p.Title = plt.plot.Title
+	p.BackgroundColor = plt.plot.BackgroundColor
+	p.Legend = plt.plot.Legend
+	p.TextHandler = plt.plot.TextHandler
+	p.X, p.Y = plt.plot.X, plt.plot.Y
+	if fit && !plt.xLimits {
+		p.X.Min, p.X.Max = math.Inf(1), math.Inf(-1)
+	}
+	if fit && !plt.yLimits {
+		p.Y.Min, p.Y.Max = math.Inf(1), math.Inf(-1)
+	}
+	p.Add(plt.drawn()...)
+
+	// the plotters extend the ranges they are added to
+	if !fit {
+		p.X, p.Y = plt.plot.X, plt.plot.Y
+	}
+	return p
+}
+
+type artist struct {
+	plt    *plotParameters
+	series *series
+}
+
+// the series is still in its plot, not removed nor cleared
+func (a *artist) attached() bool {
+	if a.plt == nil {
+		return false
+	}
+	for _, s := range a.plt.series {
+		if s == a.series {
+			return true
+		}
+	}
+	return false
+}
+
+// lock the figure of the series, so that the handles may be used from other goroutines
+// while the figure is drawn
+func (a *artist) lock() (unlock func()) {
+	if a.plt == nil {
+		return func() {}
+	}
+	a.plt.mu.Lock()
+	return a.plt.mu.Unlock
+}
+
+// the data of the series changed, the axis ranges must be fitted again
+func (a *artist) changed() {
+	a.plt.dirty = true
+	a.plt.stale = true
+}
+
+func (a *artist) SetLabel(label string) {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	a.plt.dirty = true
+	if a.plt.parent != nil {
+		a.plt.parent.dirty = true
+	}
+
+	a.series.label = label
+}
+
+func (a *artist) SetVisible(visible bool) {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	a.changed()
+
+	a.series.hidden = !visible
+}
+
+func (a *artist) Visible() bool {
+	defer a.lock()()
+
+	return a.attached() && !a.series.hidden
+}
+
+// remove the series from the plot and from its legend
+func (a *artist) Remove() {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	a.changed()
+
+	for i, s := range a.plt.series {
+		if s == a.series {
+			a.plt.series = append(a.plt.series[:i], a.plt.series[i+1:]...)
+			break
+		}
+	}
+	a.series.hidden = true
+}
+
+type lineArtist struct {
+	artist
+	line    *plotter.Line
+	markers *plotter.Scatter
+	spacing int // markers on every spacing-th point
+	rolling int // number of points kept, zero for all
+}
+
+func (a *lineArtist) SetData(x, y []float64) {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	if err := checkXY(x, y); err != nil {
+		a.plt.setErr(err)
+		return
+	}
+	a.changed()
+
+	pts := make(plotter.XYs, len(x))
+	for i := range pts {
+		pts[i].X = x[i]
+		pts[i].Y = y[i]
+	}
+	a.setPoints(pts)
+}
+
+// add a point at the end of the line, the axes follow the new data
+// unless their limits were given by XLim and YLim
+func (a *lineArtist) Append(x, y float64) {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	a.changed()
+
+	a.setPoints(append(a.line.XYs, plotter.XY{X: x, Y: y}))
+}
+
+// append the points received from the channel until it is closed
+func (a *lineArtist) Stream(points <-chan [2]float64) {
+	go func() {
+		for pt := range points {
+			a.Append(pt[0], pt[1])
+		}
+	}()
+}
+
+// points of the line and its markers, keeping the last ones of a rolling window
+func (a *lineArtist) setPoints(pts plotter.XYs) {
+	if a.rolling > 0 && len(pts) > a.rolling {
+		pts = pts[len(pts)-a.rolling:]
+	}
+	a.line.XYs = pts
+	if a.markers != nil {
+		spacedPts := make(plotter.XYs, (len(pts)+a.spacing-1)/a.spacing)
+		for i := range spacedPts {
+			spacedPts[i] = pts[i*a.spacing]
+		}
+		a.markers.XYs = spacedPts
+	}
+}
+
+func (a *lineArtist) Data() (x, y []float64) {
+	defer a.lock()()
+
+	if a.line == nil {
+		return nil, nil
+	}
+	x, y = make([]float64, len(a.line.XYs)), make([]float64, len(a.line.XYs))
+	for i, pt := range a.line.XYs {
+		x[i], y[i] = pt.X, pt.Y
+	}
+	return x, y
+}
+
+func (a *lineArtist) SetColor(color colorType) {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	a.plt.dirty = true
+
+	a.plt.lineOptions.useColor(color)
+	a.line.Color = color
+	if a.markers != nil {
+		a.markers.Color = color
+	}
+}
+
+func (a *lineArtist) SetLineWidth(width float64) {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	a.plt.dirty = true
+
+	a.line.Width = vg.Points(width)
+}
+
+func (a *lineArtist) SetLineStyle(style lineStyleType) {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	a.plt.dirty = true
+
+	a.line.Dashes = style
+}
+
+type scatterArtist struct {
+	artist
+	scatter *plotter.Scatter
+	options scatterOptions
+}
+
+// new positions of the points, with z coloring them as in Scatter
+func (a *scatterArtist) SetData(x, y, z []float64) {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	sc, err := newScatter(x, y, z, a.options)
+	if err != nil {
+		a.plt.setErr(err)
+		return
+	}
+	a.changed()
+
+	// the plotter is updated in place, it is also the legend thumbnail
+	*a.scatter = *sc
+	if a.options.colorBar.show {
+		a.plt.colorBar.min, a.plt.colorBar.max = a.options.zRange(z)
+	}
+}
+
+// color of all the points, replacing the colors given by z
+func (a *scatterArtist) SetColor(color colorType) {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	a.plt.dirty = true
+
+	a.options.color = color
+	a.options.gradient = colorgrad.Gradient{}
+	a.scatter.Color = color
+	a.scatter.GlyphStyleFunc = nil
+}
+
+func (a *scatterArtist) SetMarkerSize(size float64) {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	a.plt.dirty = true
+
+	a.options.markerSize = vg.Points(size)
+	a.scatter.Radius = a.options.markerSize
+	if f := a.scatter.GlyphStyleFunc; f != nil {
+		a.scatter.GlyphStyleFunc = func(i int) draw.GlyphStyle {
+			sty := f(i)
+			sty.Radius = a.options.markerSize
+			return sty
+		}
+	}
+}
+
+type contourArtist struct {
+	artist
+	fill    *plotter.HeatMap // filled levels of ContourF
+	lines   *plotter.Contour // contour lines
+	nLevels int
+}
+
+// new grid of values, with the levels spread over the new range
+func (a *contourArtist) SetData(x, y, z *mat.Dense) {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	if err := checkGrid(x, y, z); err != nil {
+		a.plt.setErr(err)
+		return
+	}
+	a.changed()
+
+	m := unitGrid{x: x, y: y, Data: z}
+	min, max := mat.Min(z), mat.Max(z)
+	if a.fill != nil {
+		a.fill.GridXYZ = m
+		a.fill.Min, a.fill.Max = min, max
+	}
+	if a.lines != nil {
+		a.lines.GridXYZ = m
+		a.lines.Levels = Linspace(min, max, a.nLevels)
+		a.lines.Min, a.lines.Max = min, max
+	}
+	if a.plt.colorBar.show {
+		a.plt.colorBar.min, a.plt.colorBar.max = min, max
+	}
+}
+
+type imageArtist struct {
+	artist
+	image *plotter.Image
+}
+
+func (a *imageArtist) SetData(x []*mat.Dense) {
+	defer a.lock()()
+
+	if !a.attached() {
+		return
+	}
+	if err := checkImage(x); err != nil {
+		a.plt.setErr(err)
+		return
+	}
+	a.changed()
+
+	*a.image = *newImage(x)
+}
@@ -0,0 +1,41 @@
+package plotter
+
+import (
+	"testing"
+
+	"github.com/mazznoer/colorgrad"
+)
+
+func TestScatterSetDataColorbar(t *testing.T) {
+	p := NewPlot().(*plotParameters)
+	grad := colorgrad.Viridis()
+	c := p.Scatter([]float64{1, 2, 3}, []float64{1, 2, 3}, []float64{0, 5, 10},
+		WithScatterGradient(grad), WithScatterColorbar(Vertical))
+	if p.colorBar.min != 0 || p.colorBar.max != 10 {
+		t.Fatalf("colorbar range [%g, %g], want [0, 10]", p.colorBar.min, p.colorBar.max)
+	}
+
+	c.SetData([]float64{1, 2, 3}, []float64{1, 2, 3}, []float64{100, 150, 200})
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if p.colorBar.min != 100 || p.colorBar.max != 200 {
+		t.Fatalf("colorbar range [%g, %g] after SetData, want [100, 200]", p.colorBar.min, p.colorBar.max)
+	}
+	// the points are colored in the new range, as the colorbar
+	sc := c.(*scatterArtist).scatter
+	for i, want := range []float64{0, 0.5, 1} {
+		if got := sc.GlyphStyleFunc(i).Color; got != grad.At(want).Clamped() {
+			t.Errorf("point %d of color %v, want the gradient at %g", i, got, want)
+		}
+	}
+
+	// limits given by the user are kept
+	p = NewPlot().(*plotParameters)
+	c = p.Scatter([]float64{1, 2}, []float64{1, 2}, []float64{0, 5},
+		WithScatterGradient(grad), WithScatterColorbar(Vertical), WithScatterColorLimits(-1, 1))
+	c.SetData([]float64{1, 2}, []float64{1, 2}, []float64{100, 200})
+	if p.colorBar.min != -1 || p.colorBar.max != 1 {
+		t.Fatalf("colorbar range [%g, %g] after SetData, want the limits [-1, 1]", p.colorBar.min, p.colorBar.max)
+	}
+}
@@ -0,0 +1,348 @@
+package plotter
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+type barOptions struct {
+	width      font.Length
+	color      color.Color
+	edgeColor  color.Color
+	edgeWidth  font.Length
+	grouped    bool
+	stacked    bool
+	bottom     []float64
+	showValues bool
+	label      string
+}
+
+// bar chart added to the plot, used to place grouped and stacked bars
+type barSeries struct {
+	chart     *plotter.BarChart
+	stackedOn *barSeries
+	grouped   bool
+	autoWidth bool // width taken from the space of a category when drawn
+}
+
+// part of the space of a category taken by its bars, or shared by its grouped bars
+const barFraction = 0.8
+
+// name of the bars in the legend
+func WithBarLabel(label string) func(*barOptions) {
+	return func(bo *barOptions) {
+		bo.label = label
+	}
+}
+
+// width of the bars in points instead of a part of the space of a category
+func WithBarWidth(width float64) func(*barOptions) {
+	return func(bo *barOptions) {
+		bo.width = vg.Points(width)
+	}
+}
+
+func WithBarColor(color colorType) func(*barOptions) {
+	return func(bo *barOptions) {
+		bo.color = color
+	}
+}
+
+func WithBarEdgeColor(color colorType) func(*barOptions) {
+	return func(bo *barOptions) {
+		bo.edgeColor = color
+	}
+}
+
+func WithBarEdgeWidth(width float64) func(*barOptions) {
+	return func(bo *barOptions) {
+		bo.edgeWidth = vg.Points(width)
+	}
+}
+
+// place the bars side by side with the other grouped bars of the plot
+func WithBarGroup() func(*barOptions) {
+	return func(bo *barOptions) {
+		bo.grouped = true
+	}
+}
+
+// stack the bars on top of the previous bars of the plot
+func WithBarStacked() func(*barOptions) {
+	return func(bo *barOptions) {
+		bo.stacked = true
+	}
+}
+
+// start the bars at the given bottom values instead of zero
+func WithBarBottom(bottom []float64) func(*barOptions) {
+	return func(bo *barOptions) {
+		bo.bottom = bottom
+	}
+}
+
+// write the value of each bar at its end
+func WithBarValues() func(*barOptions) {
+	return func(bo *barOptions) {
+		bo.showValues = true
+	}
+}
+
+// parameters to vertical bar plot
+func (plt *plotParameters) Bar(labels []string, heights []float64, options ...func(*barOptions)) {
+	defer plt.lock()()
+	plt.dirty = true
+
+	if plt.addBars(labels, heights, false, options...) {
+		plt.categoryAxis(labels, false)
+	}
+}
+
+// parameters to horizontal bar plot
+func (plt *plotParameters) BarH(labels []string, heights []float64, options ...func(*barOptions)) {
+	defer plt.lock()()
+	plt.dirty = true
+
+	if plt.addBars(labels, heights, true, options...) {
+		plt.categoryAxis(labels, true)
+	}
+}
+
+// name the categories along the x-axis, or the y-axis when horizontal,
+// with half a category of space around the first and last ones
+func (plt *plotParameters) categoryAxis(labels []string, horizontal bool) {
+	axis := &plt.plot.X
+	if horizontal {
+		axis = &plt.plot.Y
+		plt.plot.NominalY(labels...)
+	} else {
+		plt.plot.NominalX(labels...)
+	}
+	axis.Min = math.Min(axis.Min, -0.5)
+	axis.Max = math.Max(axis.Max, float64(len(labels))-0.5)
+}
+
+// make a bar chart plotter and add it to the plot
+func (plt *plotParameters) addBars(labels []string, heights []float64, horizontal bool, options ...func(*barOptions)) bool {
+	// default options
+	bo := barOptions{
+		edgeColor: Black,
+		edgeWidth: vg.Points(0.5),
+	}
+
+	// apply additional options
+	for _, option := range options {
+		option(&bo)
+	}
+
+	if len(heights) == 0 {
+		plt.setErr(ErrEmptyData)
+		return false
+	}
+	if len(labels) != len(heights) {
+		plt.setErr(fmt.Errorf("plotter: len(labels) = %d and len(heights) = %d mismatch", len(labels), len(heights)))
+		return false
+	}
+	if bo.bottom != nil && len(bo.bottom) != len(heights) {
+		plt.setErr(fmt.Errorf("plotter: len(bottom) = %d and len(heights) = %d mismatch", len(bo.bottom), len(heights)))
+		return false
+	}
+
+	// the bars without a width are sized when drawn, the chart needs a positive one
+	width := bo.width
+	if width == 0 {
+		width = 1
+	}
+	bc, err := plotter.NewBarChart(plotter.Values(heights), width)
+	if err != nil {
+		plt.setErr(err)
+		return false
+	}
+
+	// invisible bars, never added to the plot, as the bottom of the bars
+	var bottom *plotter.BarChart
+	if bo.bottom != nil {
+		if bottom, err = plotter.NewBarChart(plotter.Values(bo.bottom), width); err != nil {
+			plt.setErr(err)
+			return false
+		}
+	}
+
+	// stack on the last bars, with the given bottom added to their height
+	series := &barSeries{chart: bc, grouped: bo.grouped && !bo.stacked, autoWidth: bo.width == 0}
+	if bo.stacked && len(plt.bars) > 0 {
+		series.stackedOn = plt.bars[len(plt.bars)-1]
+		if bottom != nil {
+			bottom.StackOn(series.stackedOn.chart)
+		} else {
+			bottom = series.stackedOn.chart
+		}
+	}
+	if bottom != nil {
+		bc.StackOn(bottom)
+	}
+
+	// automatic color assignment
+	if bo.color == nil {
+		bo.color = plt.nextColor()
+	} else {
+		plt.lineOptions.useColor(bo.color)
+	}
+	bc.Color = bo.color
+	bc.LineStyle.Color = bo.edgeColor
+	bc.LineStyle.Width = bo.edgeWidth
+	bc.Horizontal = horizontal
+
+	plt.bars = append(plt.bars, series)
+	plt.layoutBars()
+
+	// add the plotters to the plot
+	plotters := []plot.Plotter{bc}
+	if bo.showValues {
+		plotters = append(plotters, &barValues{chart: bc, style: plt.plot.Y.Tick.Label})
+	}
+	plt.add(bo.label, []plot.Thumbnailer{bc}, plotters...)
+	return true
+}
+
+// width of the bars without a width from the space of a category on the data canvas,
+// the grouped bars share that space
+func (plt *plotParameters) sizeBars(c draw.Canvas) {
+	if len(plt.bars) == 0 {
+		return
+	}
+
+	groups := 0
+	for _, b := range plt.bars {
+		if b.grouped {
+			groups++
+		}
+	}
+	trX, trY := plt.plot.Transforms(&c)
+	for _, b := range plt.bars {
+		if !b.autoWidth {
+			continue
+		}
+		spacing := trX(1) - trX(0)
+		if b.chart.Horizontal {
+			spacing = trY(1) - trY(0)
+		}
+		width := vg.Length(barFraction * math.Abs(float64(spacing)))
+
+		switch {
+		case b.grouped:
+			b.chart.Width = width / vg.Length(groups)
+		case b.stackedOn != nil:
+			b.chart.Width = b.stackedOn.chart.Width
+		default:
+			b.chart.Width = width
+		}
+	}
+	plt.layoutBars()
+}
+
+// place the grouped bars side by side around their category
+// and move the stacked bars along with their base
+func (plt *plotParameters) layoutBars() {
+	var total vg.Length
+	for _, b := range plt.bars {
+		if b.grouped {
+			total += b.chart.Width
+		}
+	}
+
+	offset := -total / 2
+	for _, b := range plt.bars {
+		switch {
+		case b.grouped:
+			b.chart.Offset = offset + b.chart.Width/2
+			offset += b.chart.Width
+		case b.stackedOn != nil:
+			b.chart.Offset = b.stackedOn.chart.Offset
+		}
+	}
+}
+
+// plotter that writes the value of each bar at its end
+type barValues struct {
+	chart *plotter.BarChart
+	style draw.TextStyle
+}
+
+// space between the end of the bar and its value
+const barValuePadding = vg.Length(2)
+
+func (bv *barValues) Plot(c draw.Canvas, p *plot.Plot) {
+	trCat, trVal := p.Transforms(&c)
+	if bv.chart.Horizontal {
+		trCat, trVal = trVal, trCat
+	}
+
+	for i, v := range bv.chart.Values {
+		cat := trCat(bv.chart.XMin+float64(i)) + bv.chart.Offset
+		end := trVal(bv.chart.BarHeight(i))
+
+		pt := vg.Point{X: cat, Y: end}
+		if bv.chart.Horizontal {
+			pt = vg.Point{X: end, Y: cat}
+		}
+		if c.Contains(pt) {
+			sty, offset := bv.textStyle(v)
+			c.FillText(sty, pt.Add(offset), bv.label(i))
+		}
+	}
+}
+
+// glyph boxes so that the values are not clipped at the edges of the plot
+func (bv *barValues) GlyphBoxes(p *plot.Plot) []plot.GlyphBox {
+	boxes := make([]plot.GlyphBox, len(bv.chart.Values))
+	for i, v := range bv.chart.Values {
+		cat := bv.chart.XMin + float64(i)
+		end := bv.chart.BarHeight(i)
+
+		sty, offset := bv.textStyle(v)
+		rect := sty.Rectangle(bv.label(i))
+		rect.Min, rect.Max = rect.Min.Add(offset), rect.Max.Add(offset)
+		if !bv.chart.Horizontal {
+			boxes[i].X, boxes[i].Y = p.X.Norm(cat), p.Y.Norm(end)
+			rect.Min.X, rect.Max.X = 0, 0
+		} else {
+			boxes[i].X, boxes[i].Y = p.X.Norm(end), p.Y.Norm(cat)
+			rect.Min.Y, rect.Max.Y = 0, 0
+		}
+		boxes[i].Rectangle = rect
+	}
+	return boxes
+}
+
+// text of the value of bar i
+func (bv *barValues) label(i int) string {
+	return fmt.Sprintf("%g", bv.chart.Values[i])
+}
+
+// text alignment and offset from the end of the bar, outwards from the bar
+func (bv *barValues) textStyle(v float64) (draw.TextStyle, vg.Point) {
+	sty := bv.style
+	if !bv.chart.Horizontal {
+		sty.XAlign, sty.YAlign = draw.XCenter, draw.YBottom
+		if v < 0 {
+			sty.YAlign = draw.YTop
+			return sty, vg.Point{Y: -barValuePadding}
+		}
+		return sty, vg.Point{Y: barValuePadding}
+	}
+
+	sty.XAlign, sty.YAlign = draw.XLeft, draw.YCenter
+	if v < 0 {
+		sty.XAlign = draw.XRight
+		return sty, vg.Point{X: -barValuePadding}
+	}
+	return sty, vg.Point{X: barValuePadding}
+}
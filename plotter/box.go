@@ -0,0 +1,322 @@
+package plotter
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+type boxOptions struct {
+	width         float64
+	color         color.Color
+	whisker       float64
+	percentiles   [2]float64
+	notch         bool
+	horizontal    bool
+	showOutliers  bool
+	outlierMarker draw.GlyphDrawer
+	outlierSize   font.Length
+	label         string
+}
+
+// name of the box plot in the legend
+func WithBoxLabel(label string) func(*boxOptions) {
+	return func(bo *boxOptions) {
+		bo.label = label
+	}
+}
+
+// box width as a fraction of the space between categories
+func WithBoxWidth(width float64) func(*boxOptions) {
+	return func(bo *boxOptions) {
+		bo.width = width
+	}
+}
+
+func WithBoxColor(color colorType) func(*boxOptions) {
+	return func(bo *boxOptions) {
+		bo.color = color
+	}
+}
+
+// whiskers reach the furthest data within k times the interquartile range from the box
+func WithWhiskerIQR(k float64) func(*boxOptions) {
+	return func(bo *boxOptions) {
+		bo.whisker = k
+		bo.percentiles = [2]float64{}
+	}
+}
+
+// whiskers reach the given low and high percentiles of the data, between 0 and 100
+func WithWhiskerPercentiles(low, high float64) func(*boxOptions) {
+	return func(bo *boxOptions) {
+		bo.percentiles = [2]float64{low, high}
+	}
+}
+
+// notch the box around the median to show its confidence interval
+func WithBoxNotch() func(*boxOptions) {
+	return func(bo *boxOptions) {
+		bo.notch = true
+	}
+}
+
+func WithBoxHorizontal() func(*boxOptions) {
+	return func(bo *boxOptions) {
+		bo.horizontal = true
+	}
+}
+
+func WithOutlierMarker(marker markerType) func(*boxOptions) {
+	return func(bo *boxOptions) {
+		bo.outlierMarker = marker
+	}
+}
+
+func WithoutOutliers() func(*boxOptions) {
+	return func(bo *boxOptions) {
+		bo.showOutliers = false
+	}
+}
+
+// summary of a group of data drawn by box and violin plots
+type boxStats struct {
+	median, q1, q3 float64
+	low, high      float64 // ends of the whiskers
+	notch          float64 // half height of the notch around the median
+	outliers       []float64
+}
+
+// parameters to box plot
+func (plt *plotParameters) BoxPlot(groups [][]float64, labels []string, options ...func(*boxOptions)) {
+	defer plt.lock()()
+	plt.dirty = true
+
+	// default options
+	bo := boxOptions{
+		width:         0.5,
+		whisker:       1.5,
+		showOutliers:  true,
+		outlierMarker: Circle,
+		outlierSize:   vg.Points(2.5),
+	}
+
+	// apply additional options
+	for _, option := range options {
+		option(&bo)
+	}
+
+	labels, err := checkGroups(groups, labels)
+	if err != nil {
+		plt.setErr(err)
+		return
+	}
+	if bo.percentiles != [2]float64{} && !(0 <= bo.percentiles[0] && bo.percentiles[0] < bo.percentiles[1] && bo.percentiles[1] <= 100) {
+		plt.setErr(fmt.Errorf("plotter: invalid whisker percentiles %v", bo.percentiles))
+		return
+	}
+
+	stats := make([]boxStats, len(groups))
+	for i, group := range groups {
+		stats[i] = newBoxStats(group, bo.whisker, bo.percentiles)
+	}
+
+	// automatic color assignment
+	if bo.color == nil {
+		bo.color = plt.nextColor()
+	} else {
+		plt.lineOptions.useColor(bo.color)
+	}
+
+	b := &boxPlot{
+		stats:      stats,
+		width:      bo.width,
+		fill:       withAlpha(bo.color, 0.4),
+		notch:      bo.notch,
+		horizontal: bo.horizontal,
+		line:       draw.LineStyle{Color: Black, Width: vg.Points(1)},
+	}
+	if bo.showOutliers {
+		b.outlier = draw.GlyphStyle{Color: bo.color, Radius: bo.outlierSize, Shape: bo.outlierMarker}
+	}
+
+	// add the plotters to the plot
+	plt.add(bo.label, []plot.Thumbnailer{b}, b)
+	plt.categoryAxis(labels, bo.horizontal)
+}
+
+// check the groups of data and give default labels to the groups
+func checkGroups(groups [][]float64, labels []string) ([]string, error) {
+	if len(groups) == 0 {
+		return nil, ErrEmptyData
+	}
+	if labels == nil {
+		labels = make([]string, len(groups))
+		for i := range labels {
+			labels[i] = strconv.Itoa(i + 1)
+		}
+	}
+	if len(labels) != len(groups) {
+		return nil, fmt.Errorf("plotter: len(labels) = %d and len(groups) = %d mismatch", len(labels), len(groups))
+	}
+	for _, group := range groups {
+		if len(group) == 0 {
+			return nil, ErrEmptyData
+		}
+		for _, v := range group {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("plotter: group data must be finite")
+			}
+		}
+	}
+	return labels, nil
+}
+
+// quartiles, whiskers and outliers of the data
+func newBoxStats(data []float64, whisker float64, percentiles [2]float64) boxStats {
+	sorted := append([]float64(nil), data...)
+	sort.Float64s(sorted)
+
+	var s boxStats
+	s.q1 = stat.Quantile(0.25, stat.LinInterp, sorted, nil)
+	s.median = stat.Quantile(0.5, stat.LinInterp, sorted, nil)
+	s.q3 = stat.Quantile(0.75, stat.LinInterp, sorted, nil)
+	iqr := s.q3 - s.q1
+	s.notch = 1.57 * iqr / math.Sqrt(float64(len(sorted)))
+
+	if percentiles != [2]float64{} {
+		s.low = stat.Quantile(percentiles[0]/100, stat.LinInterp, sorted, nil)
+		s.high = stat.Quantile(percentiles[1]/100, stat.LinInterp, sorted, nil)
+	} else {
+		// furthest data within the whisker reach from the box
+		s.low, s.high = s.q1, s.q3
+		for _, v := range sorted {
+			if v >= s.q1-whisker*iqr {
+				s.low = math.Min(v, s.q1)
+				break
+			}
+		}
+		for i := len(sorted) - 1; i >= 0; i-- {
+			if sorted[i] <= s.q3+whisker*iqr {
+				s.high = math.Max(sorted[i], s.q3)
+				break
+			}
+		}
+	}
+
+	for _, v := range sorted {
+		if v < s.low || v > s.high {
+			s.outliers = append(s.outliers, v)
+		}
+	}
+	return s
+}
+
+// plotter that draws a box with whiskers for each category
+type boxPlot struct {
+	stats      []boxStats
+	width      float64
+	fill       color.Color
+	line       draw.LineStyle
+	outlier    draw.GlyphStyle
+	notch      bool
+	horizontal bool
+}
+
+func (b *boxPlot) Plot(c draw.Canvas, p *plot.Plot) {
+	trCat, trVal := p.Transforms(&c)
+	if b.horizontal {
+		trCat, trVal = trVal, trCat
+	}
+
+	// points given as category and value coordinates
+	point := func(cat, val vg.Length) vg.Point {
+		if b.horizontal {
+			return vg.Point{X: val, Y: cat}
+		}
+		return vg.Point{X: cat, Y: val}
+	}
+
+	for i, s := range b.stats {
+		cat := float64(i)
+		mid := trCat(cat)
+		left, right := trCat(cat-b.width/2), trCat(cat+b.width/2)
+		capLeft, capRight := trCat(cat-b.width/4), trCat(cat+b.width/4)
+		q1, q3, med := trVal(s.q1), trVal(s.q3), trVal(s.median)
+
+		// box, narrowed around the median when notched
+		var box []vg.Point
+		if b.notch {
+			inLeft, inRight := trCat(cat-b.width/4), trCat(cat+b.width/4)
+			lo, hi := trVal(math.Max(s.median-s.notch, s.q1)), trVal(math.Min(s.median+s.notch, s.q3))
+			box = []vg.Point{
+				point(left, q1), point(left, lo), point(inLeft, med), point(left, hi), point(left, q3),
+				point(right, q3), point(right, hi), point(inRight, med), point(right, lo), point(right, q1),
+			}
+			left, right = inLeft, inRight
+		} else {
+			box = []vg.Point{point(left, q1), point(left, q3), point(right, q3), point(right, q1)}
+		}
+		c.FillPolygon(b.fill, c.ClipPolygonXY(box))
+		c.StrokeLines(b.line, c.ClipLinesXY(append(box, box[0]))...)
+
+		// median line
+		medLine := b.line
+		medLine.Width *= 2
+		c.StrokeLines(medLine, c.ClipLinesXY([]vg.Point{point(left, med), point(right, med)})...)
+
+		// whiskers with caps
+		low, high := trVal(s.low), trVal(s.high)
+		c.StrokeLines(b.line, c.ClipLinesXY(
+			[]vg.Point{point(mid, q1), point(mid, low)},
+			[]vg.Point{point(mid, q3), point(mid, high)},
+			[]vg.Point{point(capLeft, low), point(capRight, low)},
+			[]vg.Point{point(capLeft, high), point(capRight, high)},
+		)...)
+
+		// outliers
+		if b.outlier.Shape != nil {
+			for _, v := range s.outliers {
+				pt := point(mid, trVal(v))
+				if c.Contains(pt) {
+					c.DrawGlyph(b.outlier, pt)
+				}
+			}
+		}
+	}
+}
+
+func (b *boxPlot) DataRange() (xmin, xmax, ymin, ymax float64) {
+	catMin, catMax := -0.5, float64(len(b.stats))-0.5
+	valMin, valMax := math.Inf(1), math.Inf(-1)
+	for _, s := range b.stats {
+		valMin, valMax = math.Min(valMin, s.low), math.Max(valMax, s.high)
+		if b.outlier.Shape != nil && len(s.outliers) > 0 {
+			valMin = math.Min(valMin, s.outliers[0])
+			valMax = math.Max(valMax, s.outliers[len(s.outliers)-1])
+		}
+	}
+	if b.horizontal {
+		return valMin, valMax, catMin, catMax
+	}
+	return catMin, catMax, valMin, valMax
+}
+
+func (b *boxPlot) Thumbnail(c *draw.Canvas) {
+	pts := []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y},
+		{X: c.Min.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Min.Y},
+	}
+	c.FillPolygon(b.fill, c.ClipPolygonY(pts))
+	c.StrokeLines(b.line, c.ClipLinesY(append(pts, pts[0]))...)
+}
@@ -7,7 +7,6 @@ import (
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
-	"gonum.org/v1/plot/vg/vgimg"
 )
 
 type positionType string
@@ -17,7 +16,10 @@ var (
 	Horizontal positionType = "horizontal"
 )
 
-func (plt *plotParameters) drawVerticalColorBar(xwidth, ywidth font.Length) *vgimg.Canvas {
+// spacing between plot and colorbar
+const colorBarSpacing = font.Length(1.5) * vg.Centimeter
+
+func (plt *plotParameters) drawVerticalColorBar(dCanvas vg.Canvas, xwidth, ywidth font.Length) {
 	grad, _ := colorgrad.NewGradient().
 		Colors(plt.colorBar.gradient.Colors(1000)...).
 		Domain(plt.colorBar.min, plt.colorBar.max).Build()
@@ -34,14 +36,8 @@ func (plt *plotParameters) drawVerticalColorBar(xwidth, ywidth font.Length) *vgi
 	l.Vertical = true
 	c.Add(l)
 
-	// spacing between plot and colorbar
-	spacing := font.Length(1.5) * vg.Centimeter
-
-	img := vgimg.New(xwidth+1.35*spacing, ywidth)
-	dCanvas := draw.New(img)
-
 	// draw the principal plot in the first column
-	plt.plot.Draw(draw.Canvas{
+	plt.draw(draw.Canvas{
 		Canvas: dCanvas,
 		Rectangle: vg.Rectangle{
 			Min: vg.Point{X: 0, Y: 0},
@@ -53,15 +49,13 @@ func (plt *plotParameters) drawVerticalColorBar(xwidth, ywidth font.Length) *vgi
 	c.Draw(draw.Canvas{
 		Canvas: dCanvas,
 		Rectangle: vg.Rectangle{
-			Min: vg.Point{X: (xwidth + 0.3*spacing), Y: vg.Centimeter},
-			Max: vg.Point{X: (xwidth + 1.3*spacing), Y: 0.93 * ywidth},
+			Min: vg.Point{X: (xwidth + 0.3*colorBarSpacing), Y: vg.Centimeter},
+			Max: vg.Point{X: (xwidth + 1.3*colorBarSpacing), Y: 0.93 * ywidth},
 		},
 	})
-
-	return img
 }
 
-func (plt *plotParameters) drawHorizontalColorBar(xwidth, ywidth font.Length) *vgimg.Canvas {
+func (plt *plotParameters) drawHorizontalColorBar(dCanvas vg.Canvas, xwidth, ywidth font.Length) {
 	grad, _ := colorgrad.NewGradient().
 		Colors(plt.colorBar.gradient.Colors(1000)...).
 		Domain(plt.colorBar.min, plt.colorBar.max).Build()
@@ -77,18 +71,12 @@ func (plt *plotParameters) drawHorizontalColorBar(xwidth, ywidth font.Length) *v
 	l.ColorMap.SetMax(plt.colorBar.max)
 	c.Add(l)
 
-	// spacing between plot and colorbar
-	spacing := font.Length(1.5) * vg.Centimeter
-
-	img := vgimg.New(xwidth, ywidth+spacing)
-	dCanvas := draw.New(img)
-
 	// draw the principal plot in the first row
-	plt.plot.Draw(draw.Canvas{
+	plt.draw(draw.Canvas{
 		Canvas: dCanvas,
 		Rectangle: vg.Rectangle{
-			Min: vg.Point{X: 0, Y: spacing},
-			Max: vg.Point{X: xwidth, Y: ywidth + spacing},
+			Min: vg.Point{X: 0, Y: colorBarSpacing},
+			Max: vg.Point{X: xwidth, Y: ywidth + colorBarSpacing},
 		},
 	})
 
@@ -100,6 +88,4 @@ func (plt *plotParameters) drawHorizontalColorBar(xwidth, ywidth font.Length) *v
 			Max: vg.Point{X: 0.99 * xwidth, Y: 1.2 * vg.Centimeter},
 		},
 	})
-
-	return img
 }
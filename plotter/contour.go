@@ -11,6 +11,7 @@ type contourOptions struct {
 	gradient     colorgrad.Gradient
 	lineSettings lineSettings
 	colorBar     colorBar
+	label        string
 }
 
 type lineSettings struct {
@@ -26,6 +27,13 @@ type colorBar struct {
 	position positionType
 }
 
+// name of the contour plot in the legend
+func WithContourLabel(label string) func(*contourOptions) {
+	return func(co *contourOptions) {
+		co.label = label
+	}
+}
+
 func WithLevels(levels int) func(*contourOptions) {
 	return func(co *contourOptions) {
 		co.nLevels = levels
@@ -0,0 +1,385 @@
+package plotter
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+type errorBarOptions struct {
+	params
+	capSize     font.Length
+	xLow, xHigh []float64
+	yLow, yHigh []float64
+	withoutLine bool
+	barWidth    font.Length
+}
+
+type fillOptions struct {
+	color color.Color
+	alpha float64
+	where []bool
+	label string
+}
+
+// name of the error bar plot in the legend
+func WithErrorBarLabel(label string) func(*errorBarOptions) {
+	return func(eo *errorBarOptions) {
+		eo.label = label
+	}
+}
+
+func WithErrorBarColor(color colorType) func(*errorBarOptions) {
+	return func(eo *errorBarOptions) {
+		eo.color = color
+	}
+}
+
+// width of the line connecting the points, the error bars take the width of WithErrorBarLineWidth
+func WithErrorBarConnectorWidth(width float64) func(*errorBarOptions) {
+	return func(eo *errorBarOptions) {
+		eo.lineWidth = vg.Points(width)
+	}
+}
+
+// style of the line connecting the points
+func WithErrorBarConnectorStyle(style lineStyleType) func(*errorBarOptions) {
+	return func(eo *errorBarOptions) {
+		eo.lineStyle = style
+	}
+}
+
+func WithErrorBarMarker(marker markerType) func(*errorBarOptions) {
+	return func(eo *errorBarOptions) {
+		eo.marker = marker
+	}
+}
+
+func WithErrorBarMarkerSize(size float64) func(*errorBarOptions) {
+	return func(eo *errorBarOptions) {
+		eo.markerSize = vg.Points(size)
+	}
+}
+
+// width of the lines of the error bars, the line connecting the points
+// takes the width of WithErrorBarConnectorWidth
+func WithErrorBarLineWidth(width float64) func(*errorBarOptions) {
+	return func(eo *errorBarOptions) {
+		eo.barWidth = vg.Points(width)
+	}
+}
+
+// width of the caps at the ends of the error bars, zero for no caps
+func WithCapSize(size float64) func(*errorBarOptions) {
+	return func(eo *errorBarOptions) {
+		eo.capSize = vg.Points(size)
+	}
+}
+
+// x errors below and above each point, replacing the symmetric xerr
+func WithAsymmetricXErr(low, high []float64) func(*errorBarOptions) {
+	return func(eo *errorBarOptions) {
+		eo.xLow, eo.xHigh = low, high
+	}
+}
+
+// y errors below and above each point, replacing the symmetric yerr
+func WithAsymmetricYErr(low, high []float64) func(*errorBarOptions) {
+	return func(eo *errorBarOptions) {
+		eo.yLow, eo.yHigh = low, high
+	}
+}
+
+// draw only the error bars and markers, without the line through the points
+func WithoutErrorBarLine() func(*errorBarOptions) {
+	return func(eo *errorBarOptions) {
+		eo.withoutLine = true
+	}
+}
+
+// name of the band in the legend
+func WithFillLabel(label string) func(*fillOptions) {
+	return func(fo *fillOptions) {
+		fo.label = label
+	}
+}
+
+func WithFillColor(color colorType) func(*fillOptions) {
+	return func(fo *fillOptions) {
+		fo.color = color
+	}
+}
+
+func WithFillAlpha(alpha float64) func(*fillOptions) {
+	return func(fo *fillOptions) {
+		fo.alpha = alpha
+	}
+}
+
+// fill only where the mask is true
+func WithFillWhere(where []bool) func(*fillOptions) {
+	return func(fo *fillOptions) {
+		fo.where = where
+	}
+}
+
+// parameters to error bar plot, xerr and yerr are symmetric errors and may be nil
+func (plt *plotParameters) ErrorBar(x, y, xerr, yerr []float64, options ...func(*errorBarOptions)) {
+	defer plt.lock()()
+	plt.dirty = true
+
+	// default options
+	eo := errorBarOptions{
+		params: params{
+			lineStyle:     Solid,
+			lineWidth:     vg.Points(1.5),
+			markerSize:    vg.Points(3),
+			markerSpacing: 1,
+		},
+		capSize:  plotter.DefaultCapWidth,
+		barWidth: vg.Points(1),
+	}
+
+	// apply additional options
+	for _, option := range options {
+		option(&eo)
+	}
+
+	if err := checkXY(x, y); err != nil {
+		plt.setErr(err)
+		return
+	}
+	xErrs, err := errorsOf(len(x), xerr, eo.xLow, eo.xHigh)
+	if err != nil {
+		plt.setErr(err)
+		return
+	}
+	yErrs, err := errorsOf(len(x), yerr, eo.yLow, eo.yHigh)
+	if err != nil {
+		plt.setErr(err)
+		return
+	}
+
+	pts := make(plotter.XYs, len(x))
+	for i := range pts {
+		pts[i].X = x[i]
+		pts[i].Y = y[i]
+	}
+
+	var thumbs []plot.Thumbnailer
+	var plotters []plot.Plotter
+	if !eo.withoutLine {
+		line, err := plotter.NewLine(pts)
+		if err != nil {
+			plt.setErr(err)
+			return
+		}
+		line.LineStyle.Width = eo.lineWidth
+		line.LineStyle.Dashes = eo.lineStyle
+		thumbs = append(thumbs, line)
+		plotters = append(plotters, line)
+	}
+	if xErrs != nil {
+		bars, err := plotter.NewXErrorBars(struct {
+			plotter.XYs
+			plotter.XErrors
+		}{pts, plotter.XErrors(xErrs)})
+		if err != nil {
+			plt.setErr(err)
+			return
+		}
+		bars.CapWidth = eo.capSize
+		bars.LineStyle.Width = eo.barWidth
+		plotters = append(plotters, bars)
+	}
+	if yErrs != nil {
+		bars, err := plotter.NewYErrorBars(struct {
+			plotter.XYs
+			plotter.YErrors
+		}{pts, plotter.YErrors(yErrs)})
+		if err != nil {
+			plt.setErr(err)
+			return
+		}
+		bars.CapWidth = eo.capSize
+		bars.LineStyle.Width = eo.barWidth
+		plotters = append(plotters, bars)
+	}
+	if eo.marker != nil {
+		scatter, _ := plotter.NewScatter(pts)
+		scatter.GlyphStyle.Shape = eo.marker
+		scatter.GlyphStyle.Radius = eo.markerSize
+		thumbs = append(thumbs, scatter)
+		plotters = append(plotters, scatter)
+	}
+
+	// automatic color assignment, shared by the line, the bars and the markers
+	if eo.color == nil {
+		eo.color = plt.nextColor()
+	} else {
+		plt.lineOptions.useColor(eo.color)
+	}
+	plt.lineOptions.lastColor = eo.color
+	for _, p := range plotters {
+		switch p := p.(type) {
+		case *plotter.Line:
+			p.Color = eo.color
+		case *plotter.XErrorBars:
+			p.Color = eo.color
+		case *plotter.YErrorBars:
+			p.Color = eo.color
+		case *plotter.Scatter:
+			p.Color = eo.color
+		}
+	}
+
+	// thumb of the error bars below the line and the markers, so that
+	// the legend has a thumb even without them
+	bar := errorBarThumb{
+		LineStyle:  draw.LineStyle{Color: eo.color, Width: eo.barWidth},
+		cap:        eo.capSize,
+		horizontal: xErrs != nil && yErrs == nil,
+	}
+	thumbs = append([]plot.Thumbnailer{bar}, thumbs...)
+
+	// add the plotters to the plot
+	plt.add(eo.label, thumbs, plotters...)
+}
+
+// thumbnail of an error bar, a line across the thumb with caps at its ends
+type errorBarThumb struct {
+	draw.LineStyle
+	cap        vg.Length
+	horizontal bool
+}
+
+func (t errorBarThumb) Thumbnail(c *draw.Canvas) {
+	center := c.Center()
+	if t.horizontal {
+		half := vg.Length(math.Min(float64(t.cap), float64(c.Max.Y-c.Min.Y))) / 2
+		c.StrokeLine2(t.LineStyle, c.Min.X, center.Y, c.Max.X, center.Y)
+		c.StrokeLine2(t.LineStyle, c.Min.X, center.Y-half, c.Min.X, center.Y+half)
+		c.StrokeLine2(t.LineStyle, c.Max.X, center.Y-half, c.Max.X, center.Y+half)
+		return
+	}
+	half := vg.Length(math.Min(float64(t.cap), float64(c.Max.X-c.Min.X))) / 2
+	c.StrokeLine2(t.LineStyle, center.X, c.Min.Y, center.X, c.Max.Y)
+	c.StrokeLine2(t.LineStyle, center.X-half, c.Min.Y, center.X+half, c.Min.Y)
+	c.StrokeLine2(t.LineStyle, center.X-half, c.Max.Y, center.X+half, c.Max.Y)
+}
+
+// errors below and above each of the n points, from the symmetric
+// errors or from the asymmetric low and high errors
+func errorsOf(n int, symmetric, low, high []float64) (plotter.Errors, error) {
+	if low != nil || high != nil {
+		if len(low) != n || len(high) != n {
+			return nil, fmt.Errorf("plotter: asymmetric errors of length %d and %d for %d points", len(low), len(high), n)
+		}
+		errs := make(plotter.Errors, n)
+		for i := range errs {
+			errs[i].Low, errs[i].High = low[i], high[i]
+		}
+		return errs, nil
+	}
+
+	if symmetric == nil {
+		return nil, nil
+	}
+	if len(symmetric) != n {
+		return nil, fmt.Errorf("plotter: errors of length %d for %d points", len(symmetric), n)
+	}
+	errs := make(plotter.Errors, n)
+	for i := range errs {
+		errs[i].Low, errs[i].High = symmetric[i], symmetric[i]
+	}
+	return errs, nil
+}
+
+// parameters to the band filled between y1 and y2
+func (plt *plotParameters) FillBetween(x, y1, y2 []float64, options ...func(*fillOptions)) {
+	defer plt.lock()()
+	plt.dirty = true
+
+	// default options
+	fo := fillOptions{
+		alpha: 0.3,
+	}
+
+	// apply additional options
+	for _, option := range options {
+		option(&fo)
+	}
+
+	if err := checkXY(x, y1); err != nil {
+		plt.setErr(err)
+		return
+	}
+	if err := checkXY(x, y2); err != nil {
+		plt.setErr(err)
+		return
+	}
+	if fo.where != nil && len(fo.where) != len(x) {
+		plt.setErr(fmt.Errorf("plotter: len(where) = %d and len(x) = %d mismatch", len(fo.where), len(x)))
+		return
+	}
+
+	// one polygon for each run of points where the band is filled
+	var polygons []*plotter.Polygon
+	for start := 0; start < len(x); start++ {
+		if fo.where != nil && !fo.where[start] {
+			continue
+		}
+		end := start
+		for end+1 < len(x) && (fo.where == nil || fo.where[end+1]) {
+			end++
+		}
+
+		ring := make(plotter.XYs, 0, 2*(end-start+1))
+		for i := start; i <= end; i++ {
+			ring = append(ring, plotter.XY{X: x[i], Y: y1[i]})
+		}
+		for i := end; i >= start; i-- {
+			ring = append(ring, plotter.XY{X: x[i], Y: y2[i]})
+		}
+		polygon, err := plotter.NewPolygon(ring)
+		if err != nil {
+			plt.setErr(err)
+			return
+		}
+		polygons = append(polygons, polygon)
+		start = end
+	}
+
+	// a mask that is false everywhere leaves no band, nor a legend entry
+	if len(polygons) == 0 {
+		return
+	}
+
+	// the band takes the color of the last line unless given
+	switch {
+	case fo.color != nil:
+		plt.lineOptions.useColor(fo.color)
+	case plt.lineOptions.lastColor != nil:
+		fo.color = plt.lineOptions.lastColor
+	default:
+		fo.color = plt.nextColor()
+	}
+
+	var thumbs []plot.Thumbnailer
+	var plotters []plot.Plotter
+	for _, polygon := range polygons {
+		polygon.Color = withAlpha(fo.color, fo.alpha)
+		polygon.LineStyle = draw.LineStyle{}
+		plotters = append(plotters, polygon)
+		thumbs = []plot.Thumbnailer{polygon}
+	}
+
+	// add the plotters to the plot
+	plt.add(fo.label, thumbs, plotters...)
+}
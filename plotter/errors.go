@@ -0,0 +1,87 @@
+package plotter
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	ErrEmptyData  = errors.New("plotter: empty data")
+	ErrNilMatrix  = errors.New("plotter: nil matrix")
+	ErrLegendSize = errors.New("plotter: more legend names than plotted series")
+)
+
+// record the first error found while building the plot
+func (plt *plotParameters) setErr(err error) {
+	if plt.err == nil {
+		plt.err = err
+	}
+}
+
+// first error found while building the plot or its twins
+func (plt *plotParameters) Err() error {
+	defer plt.lock()()
+	return plt.firstErr()
+}
+
+func (plt *plotParameters) firstErr() error {
+	for _, p := range plt.withTwins() {
+		if p.err != nil {
+			return p.err
+		}
+	}
+	return nil
+}
+
+// check that x and y data can be paired
+func checkXY(x, y []float64) error {
+	if len(x) == 0 || len(y) == 0 {
+		return ErrEmptyData
+	}
+	if len(x) != len(y) {
+		return fmt.Errorf("plotter: len(x) = %d and len(y) = %d mismatch", len(x), len(y))
+	}
+	return nil
+}
+
+// check that x and y are row vectors matching the columns and rows of z
+func checkGrid(x, y, z *mat.Dense) error {
+	if x == nil || y == nil || z == nil {
+		return ErrNilMatrix
+	}
+	if z.IsEmpty() {
+		return ErrEmptyData
+	}
+	rows, cols := z.Dims()
+	if _, c := x.Dims(); c != cols {
+		return fmt.Errorf("plotter: x has %d columns, z has %d", c, cols)
+	}
+	if _, c := y.Dims(); c != rows {
+		return fmt.Errorf("plotter: y has %d columns, z has %d rows", c, rows)
+	}
+	return nil
+}
+
+// check that image channels are all present and have the same size
+func checkImage(x []*mat.Dense) error {
+	if len(x) != 1 && len(x) != 3 {
+		return fmt.Errorf("plotter: image needs 1 or 3 channels, got %d", len(x))
+	}
+	for _, m := range x {
+		if m == nil {
+			return ErrNilMatrix
+		}
+		if m.IsEmpty() {
+			return ErrEmptyData
+		}
+	}
+	rows, cols := x[0].Dims()
+	for _, m := range x[1:] {
+		if r, c := m.Dims(); r != rows || c != cols {
+			return fmt.Errorf("plotter: image channels of size %dx%d and %dx%d mismatch", rows, cols, r, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package plotter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/vgeps"
+	"gonum.org/v1/plot/vg/vgimg"
+	"gonum.org/v1/plot/vg/vgpdf"
+	"gonum.org/v1/plot/vg/vgsvg"
+)
+
+type formatType string
+
+var (
+	PNG  formatType = "png"
+	JPEG formatType = "jpg"
+	TIFF formatType = "tiff"
+	SVG  formatType = "svg"
+	PDF  formatType = "pdf"
+	EPS  formatType = "eps"
+)
+
+type saveOptions struct {
+	format formatType
+}
+
+// force the output format regardless of the file extension
+func WithFormat(format formatType) func(*saveOptions) {
+	return func(so *saveOptions) {
+		so.format = format
+	}
+}
+
+// get the output format from the file extension
+func formatFromFile(file string) (formatType, error) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(file), "."))
+	switch ext {
+	case "", "png":
+		return PNG, nil
+	case "jpg", "jpeg":
+		return JPEG, nil
+	case "tif", "tiff":
+		return TIFF, nil
+	case "svg":
+		return SVG, nil
+	case "pdf":
+		return PDF, nil
+	case "eps":
+		return EPS, nil
+	}
+	return "", fmt.Errorf("plotter: unsupported file extension %q", filepath.Ext(file))
+}
+
+// resolve the output format of a saved file
+func saveFormat(file string, options ...func(*saveOptions)) (formatType, error) {
+	so := saveOptions{}
+	for _, option := range options {
+		option(&so)
+	}
+	if so.format != "" {
+		return so.format, nil
+	}
+	return formatFromFile(file)
+}
+
+// new canvas of the gonum backend that writes the given format,
+// raster formats are drawn with the given dpi
+func newCanvas(dpi int, xwidth, ywidth vg.Length, format formatType) (vg.CanvasWriterTo, error) {
+	switch format {
+	case PNG:
+		return vgimg.PngCanvas{Canvas: newImageCanvas(dpi, xwidth, ywidth)}, nil
+	case JPEG:
+		return vgimg.JpegCanvas{Canvas: newImageCanvas(dpi, xwidth, ywidth)}, nil
+	case TIFF:
+		return vgimg.TiffCanvas{Canvas: newImageCanvas(dpi, xwidth, ywidth)}, nil
+	case SVG:
+		return vgsvg.New(xwidth, ywidth), nil
+	case PDF:
+		return vgpdf.New(xwidth, ywidth), nil
+	case EPS:
+		return vgeps.New(xwidth, ywidth), nil
+	case markedSVG:
+		return newSVGCanvas(xwidth, ywidth), nil
+	}
+	return nil, fmt.Errorf("plotter: unsupported format %q", format)
+}
+
+// new raster canvas with the given dpi
+func newImageCanvas(dpi int, xwidth, ywidth vg.Length) *vgimg.Canvas {
+	return vgimg.NewWith(vgimg.UseWH(xwidth, ywidth), vgimg.UseDPI(dpi))
+}
@@ -0,0 +1,154 @@
+package plotter
+
+import (
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+type axisType string
+
+var (
+	XAxis    axisType = "x"
+	YAxis    axisType = "y"
+	BothAxes axisType = "both"
+)
+
+type gridLinesType string
+
+var (
+	MajorLines gridLinesType = "major" // lines at the major ticks
+	MinorLines gridLinesType = "minor" // lines at the minor ticks
+	AllLines   gridLinesType = "all"   // lines at the major and minor ticks
+)
+
+type gridOptions struct {
+	axis      axisType
+	lines     gridLinesType
+	color     color.Color
+	alpha     float64
+	lineWidth vg.Length
+	lineStyle lineStyleType
+	above     bool
+}
+
+// axis whose ticks get grid lines, vertical lines for the x-axis
+func WithGridAxis(axis axisType) func(*gridOptions) {
+	return func(gro *gridOptions) {
+		gro.axis = axis
+	}
+}
+
+func WithGridLines(lines gridLinesType) func(*gridOptions) {
+	return func(gro *gridOptions) {
+		gro.lines = lines
+	}
+}
+
+func WithGridColor(color colorType) func(*gridOptions) {
+	return func(gro *gridOptions) {
+		gro.color = color
+	}
+}
+
+func WithGridAlpha(alpha float64) func(*gridOptions) {
+	return func(gro *gridOptions) {
+		gro.alpha = alpha
+	}
+}
+
+// width of the major lines, the minor lines are half as wide
+func WithGridLineWidth(width float64) func(*gridOptions) {
+	return func(gro *gridOptions) {
+		gro.lineWidth = vg.Points(width)
+	}
+}
+
+func WithGridLineStyle(style lineStyleType) func(*gridOptions) {
+	return func(gro *gridOptions) {
+		gro.lineStyle = style
+	}
+}
+
+// draw the grid above the data instead of behind it
+func WithGridAbove() func(*gridOptions) {
+	return func(gro *gridOptions) {
+		gro.above = true
+	}
+}
+
+// draw grid lines at the ticks of the axes, with both vertical and horizontal lines by default
+func (plt *plotParameters) Grid(options ...func(*gridOptions)) {
+	defer plt.lock()()
+	plt.dirty = true
+
+	// default options
+	gro := gridOptions{
+		axis:      BothAxes,
+		lines:     MajorLines,
+		color:     plotter.DefaultGridLineStyle.Color,
+		alpha:     1,
+		lineWidth: plotter.DefaultGridLineStyle.Width,
+		lineStyle: Solid,
+	}
+
+	// apply additional options
+	for _, option := range options {
+		option(&gro)
+	}
+
+	style := draw.LineStyle{
+		Color:  withAlpha(gro.color, gro.alpha),
+		Width:  gro.lineWidth,
+		Dashes: gro.lineStyle,
+	}
+	plt.grid = &gridLines{
+		vertical:   gro.axis == XAxis || gro.axis == BothAxes,
+		horizontal: gro.axis == YAxis || gro.axis == BothAxes,
+		major:      gro.lines == MajorLines || gro.lines == AllLines,
+		minor:      gro.lines == MinorLines || gro.lines == AllLines,
+		style:      style,
+		above:      gro.above,
+	}
+}
+
+// plotter that draws lines at the major and minor ticks of the axes
+type gridLines struct {
+	vertical, horizontal bool
+	major, minor         bool
+	style                draw.LineStyle
+	above                bool
+}
+
+func (g *gridLines) Plot(c draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&c)
+
+	minor := g.style
+	minor.Width /= 2
+	styleOf := func(t plot.Tick) (draw.LineStyle, bool) {
+		if t.IsMinor() {
+			return minor, g.minor
+		}
+		return g.style, g.major
+	}
+
+	if g.vertical {
+		for _, t := range p.X.Tick.Marker.Ticks(p.X.Min, p.X.Max) {
+			x := trX(t.Value)
+			if sty, ok := styleOf(t); ok && c.ContainsX(x) {
+				c.StrokeLine2(sty, x, c.Min.Y, x, c.Max.Y)
+			}
+		}
+	}
+	if g.horizontal {
+		for _, t := range p.Y.Tick.Marker.Ticks(p.Y.Min, p.Y.Max) {
+			y := trY(t.Value)
+			if sty, ok := styleOf(t); ok && c.ContainsY(y) {
+				c.StrokeLine2(sty, c.Min.X, y, c.Max.X, y)
+			}
+		}
+	}
+}
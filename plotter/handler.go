@@ -0,0 +1,250 @@
+package plotter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// largest size in pixels, area in pixels and resolution served, so that
+// a request cannot draw a figure too large for the memory of the server
+const (
+	maxServedPixels = 8192
+	maxServedArea   = 4096 * 4096
+	maxServedDPI    = 1200
+)
+
+// content types of the output formats, in the order they are preferred
+// when the Accept header gives them the same quality
+var contentTypes = []struct {
+	format      formatType
+	contentType string
+}{
+	{PNG, "image/png"},
+	{SVG, "image/svg+xml"},
+	{PDF, "application/pdf"},
+	{JPEG, "image/jpeg"},
+	{TIFF, "image/tiff"},
+	{EPS, "application/postscript"},
+}
+
+// figure drawn by the handlers, a plot or a subplot
+type servable interface {
+	WriteTo(w io.Writer, format formatType) (int64, error)
+	FigSizePixels(xwidth, ywidth int)
+	FigDPI(dpi int)
+	Err() error
+}
+
+type figureHandler struct {
+	build func(r *http.Request) servable
+}
+
+// http.Handler drawing the plot built for each request, in the format of the format
+// query parameter or of the Accept header, with the size in pixels of the width and height
+// query parameters and the resolution of the dpi query parameter.
+// The size and resolution are set on the returned plot, so build must return a new plot
+// for each request, not one cached or shared between requests.
+func Handler(build func(r *http.Request) Plot) http.Handler {
+	return figureHandler{build: func(r *http.Request) servable {
+		if p := build(r); p != nil {
+			return p
+		}
+		return nil
+	}}
+}
+
+// http.Handler drawing the subplot built for each request, as Handler, build must
+// return a new subplot for each request
+func SubplotHandler(build func(r *http.Request) Subplot) http.Handler {
+	return figureHandler{build: func(r *http.Request) servable {
+		if p := build(r); p != nil {
+			return p
+		}
+		return nil
+	}}
+}
+
+func (h figureHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept")
+
+	format, contentType, err := negotiateFormat(r)
+	if err != nil {
+		status := http.StatusNotAcceptable
+		if r.URL.Query().Get("format") != "" {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	width, height, dpi, err := sizeQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	figure := h.build(r)
+	if figure == nil {
+		http.Error(w, "plotter: no figure for the request", http.StatusInternalServerError)
+		return
+	}
+
+	if dpi > 0 {
+		figure.FigDPI(dpi)
+	}
+	if width > 0 {
+		figure.FigSizePixels(width, height)
+	}
+	if err := figure.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := checkServedArea(figure, format); err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// drawn before writing the headers, so that drawing errors can still be reported
+	var buf bytes.Buffer
+	if _, err := figure.WriteTo(&buf, format); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Write(buf.Bytes())
+}
+
+// format of the format query parameter, or the preferred format of the Accept header,
+// PNG when the request gives neither
+func negotiateFormat(r *http.Request) (formatType, string, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		name = strings.ToLower(name)
+		if name == "jpeg" {
+			name = "jpg"
+		}
+		if name == "tif" {
+			name = "tiff"
+		}
+		for _, ct := range contentTypes {
+			if string(ct.format) == name {
+				return ct.format, ct.contentType, nil
+			}
+		}
+		return "", "", fmt.Errorf("plotter: unsupported format %q", name)
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return PNG, "image/png", nil
+	}
+
+	// quality of each content type, from the most specific media range matching it
+	type choice struct {
+		index       int
+		quality     float64
+		specificity int
+	}
+	choices := make([]choice, len(contentTypes))
+	for i := range choices {
+		choices[i] = choice{index: i, specificity: -1}
+	}
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaRange := strings.ToLower(strings.TrimSpace(params[0]))
+		quality := 1.0
+		for _, param := range params[1:] {
+			if kv := strings.SplitN(strings.TrimSpace(param), "=", 2); len(kv) == 2 && strings.ToLower(kv[0]) == "q" {
+				if q, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		for i, ct := range contentTypes {
+			specificity := -1
+			switch {
+			case mediaRange == ct.contentType:
+				specificity = 2
+			case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(ct.contentType, strings.TrimSuffix(mediaRange, "*")):
+				specificity = 1
+			case mediaRange == "*/*":
+				specificity = 0
+			}
+			if specificity > choices[i].specificity {
+				choices[i].specificity = specificity
+				choices[i].quality = quality
+			}
+		}
+	}
+
+	sort.SliceStable(choices, func(i, j int) bool {
+		if choices[i].quality != choices[j].quality {
+			return choices[i].quality > choices[j].quality
+		}
+		return choices[i].specificity > choices[j].specificity
+	})
+	if best := choices[0]; best.specificity >= 0 && best.quality > 0 {
+		ct := contentTypes[best.index]
+		return ct.format, ct.contentType, nil
+	}
+	return "", "", fmt.Errorf("plotter: no supported format in Accept %q", accept)
+}
+
+// size in pixels and resolution given by the query, zero when not given
+func sizeQuery(r *http.Request) (width, height, dpi int, err error) {
+	query := r.URL.Query()
+	parse := func(name string, max int) (int, error) {
+		value := query.Get(name)
+		if value == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 || n > max {
+			return 0, fmt.Errorf("plotter: invalid %s %q", name, value)
+		}
+		return n, nil
+	}
+
+	if width, err = parse("width", maxServedPixels); err != nil {
+		return 0, 0, 0, err
+	}
+	if height, err = parse("height", maxServedPixels); err != nil {
+		return 0, 0, 0, err
+	}
+	if dpi, err = parse("dpi", maxServedDPI); err != nil {
+		return 0, 0, 0, err
+	}
+	if (width == 0) != (height == 0) {
+		return 0, 0, 0, fmt.Errorf("plotter: width and height must be given together")
+	}
+	if width*height > maxServedArea {
+		return 0, 0, 0, fmt.Errorf("plotter: figure of %dx%d px larger than %d px", width, height, maxServedArea)
+	}
+	return width, height, dpi, nil
+}
+
+// check the area in pixels of the figure at its resolution, which the dpi
+// alone may make too large, vector formats are drawn without a raster
+func checkServedArea(figure servable, format formatType) error {
+	switch format {
+	case SVG, PDF, EPS:
+		return nil
+	}
+	v, ok := figure.(viewable)
+	if !ok {
+		return nil
+	}
+	mu := v.locker()
+	mu.Lock()
+	width, height := v.rasterSize()
+	mu.Unlock()
+	if width*height > maxServedArea {
+		return fmt.Errorf("plotter: figure of %dx%d px larger than %d px", width, height, maxServedArea)
+	}
+	return nil
+}
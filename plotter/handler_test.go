@@ -0,0 +1,158 @@
+package plotter
+
+import (
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newTestPlot() Plot {
+	p := NewPlot()
+	p.Plot([]float64{1, 2, 3}, []float64{1, 4, 9})
+	return p
+}
+
+// response of the handler of the plot to a GET of the target with the Accept header
+func serve(h http.Handler, target, accept string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandlerFormat(t *testing.T) {
+	h := Handler(func(r *http.Request) Plot { return newTestPlot() })
+	tests := []struct {
+		target, accept string
+		status         int
+		contentType    string
+	}{
+		{"/", "", http.StatusOK, "image/png"},
+		{"/?format=svg", "", http.StatusOK, "image/svg+xml"},
+		{"/?format=JPEG", "", http.StatusOK, "image/jpeg"},
+		{"/?format=svg", "image/png", http.StatusOK, "image/svg+xml"},
+		{"/?format=bmp", "", http.StatusBadRequest, ""},
+		{"/", "image/svg+xml", http.StatusOK, "image/svg+xml"},
+		{"/", "image/svg+xml;q=0.5, image/png;q=0.9", http.StatusOK, "image/png"},
+		{"/", "image/svg+xml ; q=0.9, image/*;q=0.5", http.StatusOK, "image/svg+xml"},
+		{"/", "application/pdf, */*;q=0.1", http.StatusOK, "application/pdf"},
+		{"/", "image/*", http.StatusOK, "image/png"},
+		{"/", "image/png;q=0, */*", http.StatusOK, "image/svg+xml"},
+		{"/", "text/html", http.StatusNotAcceptable, ""},
+		{"/", "image/png;q=0", http.StatusNotAcceptable, ""},
+	}
+	for _, test := range tests {
+		w := serve(h, test.target, test.accept)
+		if w.Code != test.status {
+			t.Errorf("%s with Accept %q: status %d, want %d", test.target, test.accept, w.Code, test.status)
+			continue
+		}
+		if vary := w.Header().Get("Vary"); vary != "Accept" {
+			t.Errorf("%s with Accept %q: Vary %q", test.target, test.accept, vary)
+		}
+		if test.status != http.StatusOK {
+			continue
+		}
+		if ct := w.Header().Get("Content-Type"); ct != test.contentType {
+			t.Errorf("%s with Accept %q: Content-Type %q, want %q", test.target, test.accept, ct, test.contentType)
+		}
+		if cl := w.Header().Get("Content-Length"); cl != strconv.Itoa(w.Body.Len()) {
+			t.Errorf("%s with Accept %q: Content-Length %q for %d bytes", test.target, test.accept, cl, w.Body.Len())
+		}
+	}
+}
+
+func TestHandlerSize(t *testing.T) {
+	h := Handler(func(r *http.Request) Plot { return newTestPlot() })
+	w := serve(h, "/?width=300&height=200", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	img, err := png.Decode(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size != image.Pt(300, 200) {
+		t.Fatalf("image of %v px, want 300x200", size)
+	}
+}
+
+func TestHandlerLimits(t *testing.T) {
+	h := Handler(func(r *http.Request) Plot { return newTestPlot() })
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{"width=300", http.StatusBadRequest},
+		{"height=200", http.StatusBadRequest},
+		{"width=0&height=200", http.StatusBadRequest},
+		{"width=-300&height=200", http.StatusBadRequest},
+		{"width=abc&height=200", http.StatusBadRequest},
+		{"width=8193&height=10", http.StatusBadRequest},
+		{"width=8192&height=10", http.StatusOK},
+		{"width=5000&height=5000", http.StatusBadRequest},
+		{"dpi=0", http.StatusBadRequest},
+		{"dpi=1201", http.StatusBadRequest},
+		{"dpi=600", http.StatusOK},
+		// the default figure size is too large at this resolution
+		{"dpi=1200", http.StatusRequestEntityTooLarge},
+		// vector formats are not drawn as a raster
+		{"dpi=1200&format=svg", http.StatusOK},
+		{"dpi=1200&format=pdf", http.StatusOK},
+	}
+	for _, test := range tests {
+		if w := serve(h, "/?"+test.query, ""); w.Code != test.status {
+			t.Errorf("%s: status %d, want %d", test.query, w.Code, test.status)
+		}
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	w := serve(Handler(func(r *http.Request) Plot { return nil }), "/", "")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("nil plot: status %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	w = serve(Handler(func(r *http.Request) Plot {
+		p := NewPlot()
+		p.Plot(nil, nil)
+		return p
+	}), "/", "")
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), ErrEmptyData.Error()) {
+		t.Errorf("plot with an error: status %d %q, want %d", w.Code, w.Body, http.StatusInternalServerError)
+	}
+	if ct := w.Header().Get("Content-Type"); strings.HasPrefix(ct, "image/") {
+		t.Errorf("plot with an error: Content-Type %q", ct)
+	}
+}
+
+func TestSubplotHandler(t *testing.T) {
+	h := SubplotHandler(func(r *http.Request) Subplot {
+		sp := NewSubplot(1, 2)
+		sp.Subplot(0, 0).Plot([]float64{1, 2}, []float64{1, 2})
+		sp.Subplot(0, 1).Plot([]float64{1, 2}, []float64{2, 1})
+		return sp
+	})
+	w := serve(h, "/", "image/svg+xml")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Fatalf("Content-Type %q", ct)
+	}
+	if !strings.Contains(w.Body.String(), "<svg") {
+		t.Fatal("body is not an SVG")
+	}
+
+	w = serve(SubplotHandler(func(r *http.Request) Subplot { return nil }), "/", "")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("nil subplot: status %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
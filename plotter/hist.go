@@ -0,0 +1,373 @@
+package plotter
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+type binRuleType string
+
+var (
+	Sturges          binRuleType = "sturges"           // log2(n) + 1 bins
+	Scott            binRuleType = "scott"             // bin width from the standard deviation
+	FreedmanDiaconis binRuleType = "freedman-diaconis" // bin width from the interquartile range
+)
+
+type histOptions struct {
+	bins       int
+	edges      []float64
+	rule       binRuleType
+	weights    []float64
+	density    bool
+	cumulative bool
+	step       bool
+	stacked    bool
+	color      color.Color
+	alpha      float64
+	lineWidth  font.Length
+	label      string
+}
+
+// name of the histogram in the legend
+func WithHistLabel(label string) func(*histOptions) {
+	return func(ho *histOptions) {
+		ho.label = label
+	}
+}
+
+func WithBins(bins int) func(*histOptions) {
+	return func(ho *histOptions) {
+		ho.bins = bins
+	}
+}
+
+func WithBinEdges(edges []float64) func(*histOptions) {
+	return func(ho *histOptions) {
+		ho.edges = edges
+	}
+}
+
+func WithBinRule(rule binRuleType) func(*histOptions) {
+	return func(ho *histOptions) {
+		ho.rule = rule
+	}
+}
+
+func WithWeights(weights []float64) func(*histOptions) {
+	return func(ho *histOptions) {
+		ho.weights = weights
+	}
+}
+
+// normalize the counts so that the area of the histogram is one
+func WithDensity() func(*histOptions) {
+	return func(ho *histOptions) {
+		ho.density = true
+	}
+}
+
+func WithCumulative() func(*histOptions) {
+	return func(ho *histOptions) {
+		ho.cumulative = true
+	}
+}
+
+// draw only the outline of the histogram
+func WithHistStep() func(*histOptions) {
+	return func(ho *histOptions) {
+		ho.step = true
+	}
+}
+
+// stack the histogram on top of the previous histogram of the plot, using its bins
+func WithHistStacked() func(*histOptions) {
+	return func(ho *histOptions) {
+		ho.stacked = true
+	}
+}
+
+func WithHistColor(color colorType) func(*histOptions) {
+	return func(ho *histOptions) {
+		ho.color = color
+	}
+}
+
+func WithHistAlpha(alpha float64) func(*histOptions) {
+	return func(ho *histOptions) {
+		ho.alpha = alpha
+	}
+}
+
+func WithHistLineWidth(width float64) func(*histOptions) {
+	return func(ho *histOptions) {
+		ho.lineWidth = vg.Points(width)
+	}
+}
+
+// parameters to histogram plot, returns the plotted bin heights and the bin edges
+func (plt *plotParameters) Hist(data []float64, options ...func(*histOptions)) (counts, edges []float64) {
+	defer plt.lock()()
+	plt.dirty = true
+
+	// default options
+	ho := histOptions{
+		bins:      10,
+		alpha:     1,
+		lineWidth: vg.Points(1),
+	}
+
+	// apply additional options
+	for _, option := range options {
+		option(&ho)
+	}
+
+	if len(data) == 0 {
+		plt.setErr(ErrEmptyData)
+		return nil, nil
+	}
+	if ho.weights != nil && len(ho.weights) != len(data) {
+		plt.setErr(fmt.Errorf("plotter: len(weights) = %d and len(data) = %d mismatch", len(ho.weights), len(data)))
+		return nil, nil
+	}
+
+	// stacked histograms share the bins of the histogram below
+	var below *histogram
+	if ho.stacked && len(plt.hists) > 0 {
+		below = plt.hists[len(plt.hists)-1]
+		if ho.edges != nil && !floats.Equal(ho.edges, below.edges) {
+			plt.setErr(fmt.Errorf("plotter: stacked histogram bins differ from the histogram below"))
+			return nil, nil
+		}
+		ho.edges = below.edges
+	}
+
+	edges, err := binEdges(data, ho)
+	if err != nil {
+		plt.setErr(err)
+		return nil, nil
+	}
+	counts = binCounts(data, ho.weights, edges, ho.density, ho.cumulative)
+
+	// automatic color assignment
+	if ho.color == nil {
+		ho.color = plt.nextColor()
+	} else {
+		plt.lineOptions.useColor(ho.color)
+	}
+
+	h := &histogram{
+		edges:   edges,
+		heights: counts,
+		bottoms: make([]float64, len(counts)),
+		step:    ho.step,
+		fill:    withAlpha(ho.color, ho.alpha),
+		line: draw.LineStyle{
+			Color: ho.color,
+			Width: ho.lineWidth,
+		},
+	}
+	if below != nil {
+		for i := range h.bottoms {
+			h.bottoms[i] = below.bottoms[i] + below.heights[i]
+		}
+	}
+	if !ho.step {
+		h.line.Color = Black
+		h.line.Width = vg.Points(0.5)
+	}
+	plt.hists = append(plt.hists, h)
+
+	// add the plotters to the plot
+	plt.add(ho.label, []plot.Thumbnailer{h}, h)
+
+	return counts, edges
+}
+
+// edges of the histogram bins from the explicit edges, the binning rule or the number of bins
+func binEdges(data []float64, ho histOptions) ([]float64, error) {
+	if ho.edges != nil {
+		if len(ho.edges) < 2 {
+			return nil, fmt.Errorf("plotter: bin edges must be at least two increasing values")
+		}
+		for i := 1; i < len(ho.edges); i++ {
+			if !(ho.edges[i] > ho.edges[i-1]) {
+				return nil, fmt.Errorf("plotter: bin edges must be at least two increasing values")
+			}
+		}
+		return ho.edges, nil
+	}
+
+	min, max := floats.Min(data), floats.Max(data)
+	if math.IsNaN(min) || math.IsInf(min, 0) || math.IsNaN(max) || math.IsInf(max, 0) {
+		return nil, fmt.Errorf("plotter: histogram data must be finite")
+	}
+	if min == max {
+		min, max = min-0.5, max+0.5
+	}
+
+	bins := ho.bins
+	if ho.rule != "" {
+		var err error
+		if bins, err = ruleBins(data, ho.rule, max-min); err != nil {
+			return nil, err
+		}
+	}
+	if bins < 1 {
+		return nil, fmt.Errorf("plotter: invalid number of bins %d", bins)
+	}
+	return Linspace(min, max, bins+1), nil
+}
+
+// number of bins given by a binning rule, at most one bin for each value so that
+// a spread near zero with outliers does not ask for a huge number of bins
+func ruleBins(data []float64, rule binRuleType, span float64) (int, error) {
+	n := float64(len(data))
+
+	var width float64
+	switch rule {
+	case Sturges:
+		return int(math.Ceil(math.Log2(n))) + 1, nil
+	case Scott:
+		width = 3.49 * stat.StdDev(data, nil) * math.Pow(n, -1./3)
+	case FreedmanDiaconis:
+		sorted := append([]float64(nil), data...)
+		sort.Float64s(sorted)
+		iqr := stat.Quantile(0.75, stat.Empirical, sorted, nil) - stat.Quantile(0.25, stat.Empirical, sorted, nil)
+		width = 2 * iqr * math.Pow(n, -1./3)
+	default:
+		return 0, fmt.Errorf("plotter: unknown binning rule %q", rule)
+	}
+
+	if width <= 0 || math.IsNaN(width) {
+		return 0, fmt.Errorf("plotter: bin width %g of the %s rule, the data has no spread", width, rule)
+	}
+	if bins := span / width; bins < n {
+		return int(math.Ceil(bins)), nil
+	}
+	return len(data), nil
+}
+
+// weighted counts of data in each bin, the last bin includes its right edge
+func binCounts(data, weights, edges []float64, density, cumulative bool) []float64 {
+	counts := make([]float64, len(edges)-1)
+	last := edges[len(edges)-1]
+	for i, v := range data {
+		if v < edges[0] || v > last || math.IsNaN(v) {
+			continue
+		}
+		bin := sort.SearchFloat64s(edges, v)
+		if edges[bin] != v {
+			bin--
+		}
+		if bin == len(counts) {
+			bin--
+		}
+
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		counts[bin] += w
+	}
+
+	if density {
+		total := floats.Sum(counts)
+		for i := range counts {
+			if total != 0 {
+				counts[i] /= total * (edges[i+1] - edges[i])
+			}
+		}
+	}
+
+	if cumulative {
+		sum := 0.0
+		for i := range counts {
+			if density {
+				sum += counts[i] * (edges[i+1] - edges[i])
+			} else {
+				sum += counts[i]
+			}
+			counts[i] = sum
+		}
+	}
+	return counts
+}
+
+// plotter that draws the bins of a histogram as filled bars or as a step outline
+type histogram struct {
+	edges   []float64
+	heights []float64
+	bottoms []float64
+	step    bool
+	fill    color.Color
+	line    draw.LineStyle
+}
+
+func (h *histogram) Plot(c draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&c)
+
+	if h.step {
+		var pts []vg.Point
+		for i, v := range h.heights {
+			top := trY(h.bottoms[i] + v)
+			if i == 0 {
+				pts = append(pts, vg.Point{X: trX(h.edges[i]), Y: trY(h.bottoms[i])})
+			}
+			pts = append(pts,
+				vg.Point{X: trX(h.edges[i]), Y: top},
+				vg.Point{X: trX(h.edges[i+1]), Y: top})
+		}
+		last := len(h.heights) - 1
+		pts = append(pts, vg.Point{X: trX(h.edges[last+1]), Y: trY(h.bottoms[last])})
+		c.StrokeLines(h.line, c.ClipLinesXY(pts)...)
+		return
+	}
+
+	for i, v := range h.heights {
+		x0, x1 := trX(h.edges[i]), trX(h.edges[i+1])
+		y0, y1 := trY(h.bottoms[i]), trY(h.bottoms[i]+v)
+		pts := []vg.Point{{X: x0, Y: y0}, {X: x0, Y: y1}, {X: x1, Y: y1}, {X: x1, Y: y0}}
+		c.FillPolygon(h.fill, c.ClipPolygonXY(pts))
+
+		pts = append(pts, pts[0])
+		c.StrokeLines(h.line, c.ClipLinesXY(pts)...)
+	}
+}
+
+func (h *histogram) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin, xmax = h.edges[0], h.edges[len(h.edges)-1]
+	ymin, ymax = math.Inf(1), math.Inf(-1)
+	for i, v := range h.heights {
+		ymin = math.Min(ymin, math.Min(h.bottoms[i], h.bottoms[i]+v))
+		ymax = math.Max(ymax, math.Max(h.bottoms[i], h.bottoms[i]+v))
+	}
+	return xmin, xmax, ymin, ymax
+}
+
+func (h *histogram) Thumbnail(c *draw.Canvas) {
+	if h.step {
+		y := c.Center().Y
+		c.StrokeLine2(h.line, c.Min.X, y, c.Max.X, y)
+		return
+	}
+
+	pts := []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y},
+		{X: c.Min.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Min.Y},
+	}
+	c.FillPolygon(h.fill, c.ClipPolygonY(pts))
+
+	pts = append(pts, pts[0])
+	c.StrokeLines(h.line, c.ClipLinesY(pts)...)
+}
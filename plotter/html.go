@@ -0,0 +1,338 @@
+package plotter
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// data of the figure for the script of the HTML page
+type htmlFigure struct {
+	Plots  []htmlPlot   `json:"plots"`
+	Series []htmlSeries `json:"series"`
+}
+
+// data area of a plot in SVG coordinates, with its axis ranges and scales
+type htmlPlot struct {
+	Area [4]float64 `json:"area"` // left, top, right and bottom
+	X    htmlAxis   `json:"x"`
+	Y    htmlAxis   `json:"y"`
+}
+
+type htmlAxis struct {
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+	Scale     string  `json:"scale"`
+	LinThresh float64 `json:"linthresh"`
+}
+
+// series of the figure, with the points shown by the tooltips of lines and scatter points
+type htmlSeries struct {
+	Label  string       `json:"label"`
+	Plot   int          `json:"plot"`
+	Points [][2]float64 `json:"points"`
+}
+
+// mark of the drawing of a series and of its legend entry, a group of the SVG figure
+// with the id of the series found by the script
+func htmlMark(id int) func(c vg.Canvas, legend bool) (end func()) {
+	return func(c vg.Canvas, legend bool) (end func()) {
+		// the canvas of the plotters is inside the draw canvases of the plot
+		for {
+			dc, ok := c.(draw.Canvas)
+			if !ok {
+				break
+			}
+			c = dc.Canvas
+		}
+		svg, ok := c.(*svgCanvas)
+		if !ok {
+			return func() {}
+		}
+		if legend {
+			return svg.group("legend-entry", id)
+		}
+		return svg.group("series", id)
+	}
+}
+
+// save the plot as a self-contained HTML page with its SVG figure and a script
+// showing the data under the mouse, toggling series from the legend and zooming,
+// the zoom magnifies the whole drawing with its axes and text, not the data ranges
+func (plt *plotParameters) SaveHTML(file string) error {
+	return saveHTML(plt, file)
+}
+
+// save the subplot as a self-contained HTML page, as SaveHTML of a plot
+func (plt *subplotParameters) SaveHTML(file string) error {
+	return saveHTML(plt, file)
+}
+
+func saveHTML(figure viewable, file string) error {
+	mu := figure.locker()
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := figure.firstErr(); err != nil {
+		return err
+	}
+
+	// number the series and mark their drawing, the figure is drawn as by Save
+	// from plots with the same axis ranges and the plotters inside the marks
+	var plots []*plotParameters
+	var data htmlFigure
+	for _, view := range figure.views() {
+		for _, p := range view.withTwins() {
+			if p.stale {
+				p.refit()
+			}
+			plots = append(plots, p)
+			for _, s := range p.series {
+				if s.hidden {
+					continue
+				}
+				s.mark = htmlMark(len(data.Series))
+				hs := htmlSeries{Label: s.label, Plot: len(plots) - 1}
+				if xys := pickable(s); xys != nil {
+					for i := 0; i < xys.Len(); i++ {
+						x, y := xys.XY(i)
+						hs.Points = append(hs.Points, [2]float64{x, y})
+					}
+				}
+				data.Series = append(data.Series, hs)
+			}
+		}
+	}
+	saved := make([]*plot.Plot, len(plots))
+	for i, p := range plots {
+		saved[i] = p.plot
+		p.plot = p.replot(false)
+		p.dirty = true
+	}
+	defer func() {
+		for i, p := range plots {
+			for _, s := range p.series {
+				s.mark = nil
+			}
+			p.plot = saved[i]
+			p.dirty = true
+		}
+	}()
+
+	var buf bytes.Buffer
+	if _, err := figure.writeTo(&buf, markedSVG); err != nil {
+		return err
+	}
+	svg := buf.String()
+
+	// height of the figure, to turn the drawn areas upside down as in the SVG
+	var width, height float64
+	if _, err := fmt.Sscanf(svg[strings.Index(svg, `viewBox="`):], `viewBox="0 0 %g %g"`, &width, &height); err != nil {
+		return fmt.Errorf("plotter: SVG figure without size: %v", err)
+	}
+	for _, p := range plots {
+		a := p.view.area
+		data.Plots = append(data.Plots, htmlPlot{
+			Area: [4]float64{a.Min.X.Points(), height - a.Max.Y.Points(), a.Max.X.Points(), height - a.Min.Y.Points()},
+			X:    htmlAxis{Min: p.view.x[0], Max: p.view.x[1], Scale: p.xScale.kind, LinThresh: p.xScale.linthresh},
+			Y:    htmlAxis{Min: p.view.y[0], Max: p.view.y[1], Scale: p.yScale.kind, LinThresh: p.yScale.linthresh},
+		})
+	}
+
+	var page bytes.Buffer
+	err := htmlPage.Execute(&page, struct {
+		Title  string
+		SVG    template.HTML
+		Figure htmlFigure
+	}{
+		Title:  strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+		SVG:    template.HTML(svg),
+		Figure: data,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, page.Bytes(), 0644)
+}
+
+// page of SaveHTML, with everything inline so that it opens without a server or network
+var htmlPage = template.Must(template.New("figure").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; font-family: sans-serif; }
+#figure { display: inline-block; position: relative; user-select: none; }
+#figure svg { display: block; cursor: crosshair; }
+#tooltip { position: absolute; display: none; pointer-events: none; padding: 2px 6px;
+	background: #fff; border: 1px solid #000; font-size: 12px; white-space: nowrap; }
+.legend-entry { cursor: pointer; }
+.legend-entry.off { opacity: 0.35; }
+.series.off { display: none; }
+#help { font-size: 12px; color: #666; margin: 4px; }
+</style>
+</head>
+<body>
+<div id="figure">{{.SVG}}<div id="tooltip"></div></div>
+<div id="help">Wheel to zoom the drawing, drag to pan, double click to reset, click a legend entry to hide its series.</div>
+<script>
+(function() {
+	const figure = {{.Figure}};
+	const root = document.getElementById("figure");
+	const svg = root.querySelector("svg");
+	const tooltip = document.getElementById("tooltip");
+	const home = svg.getAttribute("viewBox").split(" ").map(Number);
+	let view = home.slice();
+	let drag = null;
+	const hidden = {};
+
+	function forward(axis, v) {
+		switch (axis.scale) {
+		case "log": return Math.log(v);
+		case "symlog": return Math.sign(v) * Math.log1p(Math.abs(v) / axis.linthresh);
+		case "logit": return Math.log(v / (1 - v));
+		}
+		return v;
+	}
+	function inverse(axis, u) {
+		switch (axis.scale) {
+		case "log": return Math.exp(u);
+		case "symlog": return Math.sign(u) * axis.linthresh * Math.expm1(Math.abs(u));
+		case "logit": return 1 / (1 + Math.exp(-u));
+		}
+		return u;
+	}
+	function norm(axis, v) {
+		const lo = forward(axis, axis.min), hi = forward(axis, axis.max);
+		return (forward(axis, v) - lo) / (hi - lo);
+	}
+	function at(plot, x, y) {
+		const a = plot.area;
+		return {x: a[0] + norm(plot.x, x) * (a[2] - a[0]), y: a[3] - norm(plot.y, y) * (a[3] - a[1])};
+	}
+	function data(plot, pt) {
+		const a = plot.area;
+		const tx = (pt.x - a[0]) / (a[2] - a[0]), ty = (a[3] - pt.y) / (a[3] - a[1]);
+		const lx = forward(plot.x, plot.x.min), hx = forward(plot.x, plot.x.max);
+		const ly = forward(plot.y, plot.y.min), hy = forward(plot.y, plot.y.max);
+		return {x: inverse(plot.x, lx + tx * (hx - lx)), y: inverse(plot.y, ly + ty * (hy - ly))};
+	}
+	function inside(plot, pt) {
+		const a = plot.area;
+		return pt.x >= a[0] && pt.x <= a[2] && pt.y >= a[1] && pt.y <= a[3];
+	}
+	function format(x, y) {
+		return "x=" + Number(x.toPrecision(4)) + ", y=" + Number(y.toPrecision(4));
+	}
+
+	// point of the figure under the mouse, and size in the figure of one pixel
+	function point(e) {
+		const p = svg.createSVGPoint();
+		p.x = e.clientX;
+		p.y = e.clientY;
+		return p.matrixTransform(svg.getScreenCTM().inverse());
+	}
+	function pixel() {
+		return view[2] / svg.getBoundingClientRect().width;
+	}
+
+	function hover(e) {
+		const pt = point(e);
+		let best = null, dist = 8 * pixel();
+		figure.series.forEach(function(s, i) {
+			if (hidden[i] || !s.points) {
+				return;
+			}
+			const plot = figure.plots[s.plot];
+			s.points.forEach(function(xy) {
+				const q = at(plot, xy[0], xy[1]);
+				const d = Math.hypot(q.x - pt.x, q.y - pt.y);
+				if (inside(plot, q) && d < dist) {
+					dist = d;
+					best = (s.label ? s.label + ": " : "") + format(xy[0], xy[1]);
+				}
+			});
+		});
+		if (best === null) {
+			const plot = figure.plots.find(function(p) { return inside(p, pt); });
+			if (plot) {
+				const xy = data(plot, pt);
+				best = format(xy.x, xy.y);
+			}
+		}
+		if (best === null) {
+			tooltip.style.display = "none";
+			return;
+		}
+		const box = root.getBoundingClientRect();
+		tooltip.textContent = best;
+		tooltip.style.display = "block";
+		let left = e.clientX - box.left + 12, top = e.clientY - box.top + 12;
+		if (left + tooltip.offsetWidth > box.width) {
+			left = e.clientX - box.left - 12 - tooltip.offsetWidth;
+		}
+		if (top + tooltip.offsetHeight > box.height) {
+			top = e.clientY - box.top - 12 - tooltip.offsetHeight;
+		}
+		tooltip.style.left = left + "px";
+		tooltip.style.top = top + "px";
+	}
+
+	function setView(v) {
+		view = v;
+		svg.setAttribute("viewBox", view.join(" "));
+	}
+
+	// the wheel zooms the viewBox, so the axes and text are magnified with the data
+	svg.addEventListener("wheel", function(e) {
+		e.preventDefault();
+		const pt = point(e);
+		const f = Math.pow(1.2, Math.sign(e.deltaY));
+		setView([pt.x - (pt.x - view[0]) * f, pt.y - (pt.y - view[1]) * f, view[2] * f, view[3] * f]);
+	}, {passive: false});
+	svg.addEventListener("mousedown", function(e) {
+		drag = {x: e.clientX, y: e.clientY, view: view.slice()};
+	});
+	window.addEventListener("mouseup", function() {
+		drag = null;
+	});
+	svg.addEventListener("mousemove", function(e) {
+		if (drag) {
+			const s = pixel();
+			setView([drag.view[0] - (e.clientX - drag.x) * s, drag.view[1] - (e.clientY - drag.y) * s, view[2], view[3]]);
+		}
+		hover(e);
+	});
+	svg.addEventListener("mouseleave", function() {
+		tooltip.style.display = "none";
+	});
+	svg.addEventListener("dblclick", function() {
+		setView(home.slice());
+	});
+
+	// legend entries toggle the groups of their series
+	svg.querySelectorAll(".legend-entry").forEach(function(entry) {
+		entry.addEventListener("mousedown", function(e) {
+			e.stopPropagation();
+		});
+		entry.addEventListener("click", function() {
+			const id = entry.getAttribute("data-series");
+			hidden[id] = !hidden[id];
+			svg.querySelectorAll('[data-series="' + id + '"]').forEach(function(g) {
+				g.classList.toggle("off", hidden[id]);
+			});
+		});
+	});
+})();
+</script>
+</body>
+</html>
+`))
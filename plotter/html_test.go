@@ -0,0 +1,96 @@
+package plotter
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// SVG figure of the page saved by SaveHTML
+func htmlSVG(t *testing.T, save func(file string) error) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "figure.html")
+	if err := save(file); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(b)
+	start, end := strings.Index(page, "<svg"), strings.Index(page, "</svg>")
+	if start < 0 || end < 0 {
+		t.Fatal("page without an SVG figure")
+	}
+	return page[start : end+len("</svg>")]
+}
+
+func TestSaveHTMLRanges(t *testing.T) {
+	p := NewPlot().(*plotParameters)
+	p.ImShow([]*mat.Dense{mat.NewDense(2, 3, []float64{0, 0.5, 1, 1, 0.5, 0})})
+	file := filepath.Join(t.TempDir(), "figure.svg")
+	if err := p.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	saved := p.view
+
+	htmlSVG(t, p.SaveHTML)
+	if p.view != saved {
+		t.Fatalf("page drawn with the ranges x=%v y=%v, saved with x=%v y=%v", p.view.x, p.view.y, saved.x, saved.y)
+	}
+	if err := p.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	if p.view != saved {
+		t.Fatalf("ranges x=%v y=%v after SaveHTML, x=%v y=%v before", p.view.x, p.view.y, saved.x, saved.y)
+	}
+}
+
+func TestSaveHTMLGroups(t *testing.T) {
+	p := NewPlot()
+	p.Plot([]float64{1, 2, 3}, []float64{1, 4, 9}, WithLabel("line"), WithMarker(Circle))
+	p.Scatter([]float64{1, 2, 3}, []float64{2, 3, 4}, nil, WithScatterLabel("points"))
+	p.Plot([]float64{1, 3}, []float64{5, 5}).SetVisible(false)
+	p.Legend()
+	svg := htmlSVG(t, p.SaveHTML)
+
+	// the groups of the series and of the legend entries in a well formed document
+	groups := make(map[string][]string)
+	d := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e, ok := token.(xml.StartElement); ok && e.Name.Local == "g" {
+			var class, id string
+			for _, a := range e.Attr {
+				switch a.Name.Local {
+				case "class":
+					class = a.Value
+				case "data-series":
+					id = a.Value
+				}
+			}
+			if class != "" {
+				groups[class] = append(groups[class], id)
+			}
+		}
+	}
+
+	// the line and its markers are drawn in two groups of the series
+	if got := strings.Join(groups["series"], ","); got != "0,0,1" {
+		t.Errorf("series groups %s, want 0,0,1", got)
+	}
+	if got := strings.Join(groups["legend-entry"], ","); got != "0,1" {
+		t.Errorf("legend entry groups %s, want 0,1", got)
+	}
+}
@@ -0,0 +1,432 @@
+package plotter
+
+import (
+	"fmt"
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+type legendLocType string
+
+var (
+	UpperLeft    legendLocType = "upper left"
+	UpperCenter  legendLocType = "upper center"
+	UpperRight   legendLocType = "upper right"
+	CenterLeft   legendLocType = "center left"
+	Center       legendLocType = "center"
+	CenterRight  legendLocType = "center right"
+	LowerLeft    legendLocType = "lower left"
+	LowerCenter  legendLocType = "lower center"
+	LowerRight   legendLocType = "lower right"
+	Best         legendLocType = "best"          // inside the axes where it covers the least data
+	OutsideRight legendLocType = "outside right" // on the right of the axes
+	OutsideBelow legendLocType = "outside below" // below the axes
+)
+
+// horizontal and vertical position of the inside locations, as fractions of the free space
+var legendLocations = map[legendLocType][2]float64{
+	UpperLeft: {0, 1}, UpperCenter: {0.5, 1}, UpperRight: {1, 1},
+	CenterLeft: {0, 0.5}, Center: {0.5, 0.5}, CenterRight: {1, 0.5},
+	LowerLeft: {0, 0}, LowerCenter: {0.5, 0}, LowerRight: {1, 0},
+}
+
+// locations tried in turn by the best location
+var bestLocations = []legendLocType{
+	UpperRight, UpperLeft, LowerLeft, LowerRight, CenterRight, CenterLeft, LowerCenter, UpperCenter, Center,
+}
+
+// space between the legend and the axes
+const legendInset = 1.5 * vg.Millimeter
+
+type legendOptions struct {
+	location    legendLocType
+	columns     int
+	title       string
+	frame       bool
+	background  color.Color
+	alpha       float64
+	fontSize    vg.Length
+	markerScale float64
+}
+
+// entry of the legend with the thumbnails of its series
+type legendEntry struct {
+	name   string
+	series *series
+}
+
+// named entries of the legend and its style
+type legendBox struct {
+	entries  []legendEntry
+	labelled bool // also show the series given a label
+	options  legendOptions
+}
+
+func WithLegendLocation(location legendLocType) func(*legendOptions) {
+	return func(lo *legendOptions) {
+		lo.location = location
+	}
+}
+
+// number of columns of entries, filled from top to bottom
+func WithLegendColumns(columns int) func(*legendOptions) {
+	return func(lo *legendOptions) {
+		lo.columns = columns
+	}
+}
+
+func WithLegendTitle(title string) func(*legendOptions) {
+	return func(lo *legendOptions) {
+		lo.title = title
+	}
+}
+
+// draw the legend in a framed box with a translucent white background
+func WithLegendFrame() func(*legendOptions) {
+	return func(lo *legendOptions) {
+		lo.frame = true
+		if lo.background == nil {
+			lo.background = color.White
+			lo.alpha = 0.8
+		}
+	}
+}
+
+// fill the legend box with the given color and alpha
+func WithLegendBackground(color colorType, alpha float64) func(*legendOptions) {
+	return func(lo *legendOptions) {
+		lo.background = color
+		lo.alpha = alpha
+	}
+}
+
+// font size of the legend entries in points
+func WithLegendFontSize(size float64) func(*legendOptions) {
+	return func(lo *legendOptions) {
+		lo.fontSize = vg.Points(size)
+	}
+}
+
+// size of the markers in the legend relative to the plot
+func WithLegendMarkerScale(scale float64) func(*legendOptions) {
+	return func(lo *legendOptions) {
+		lo.markerScale = scale
+	}
+}
+
+func defaultLegendOptions() legendOptions {
+	return legendOptions{
+		location:    LowerRight,
+		columns:     1,
+		alpha:       1,
+		fontSize:    plot.NewLegend().TextStyle.Font.Size,
+		markerScale: 1,
+	}
+}
+
+// placement and style of the legend
+func (plt *plotParameters) LegendStyle(options ...func(*legendOptions)) {
+	if plt.parent != nil {
+		plt.parent.LegendStyle(options...)
+		return
+	}
+	defer plt.lock()()
+	plt.dirty = true
+
+	lo, err := newLegendOptions(options...)
+	if err != nil {
+		plt.setErr(err)
+		return
+	}
+	plt.legendBox.options = lo
+}
+
+func newLegendOptions(options ...func(*legendOptions)) (legendOptions, error) {
+	// default options
+	lo := defaultLegendOptions()
+
+	// apply additional options
+	for _, option := range options {
+		option(&lo)
+	}
+
+	if _, ok := legendLocations[lo.location]; !ok && lo.location != Best && lo.location != OutsideRight && lo.location != OutsideBelow {
+		return lo, fmt.Errorf("plotter: unknown legend location %q", lo.location)
+	}
+	if lo.columns < 1 || lo.fontSize <= 0 || lo.markerScale <= 0 {
+		return lo, fmt.Errorf("plotter: invalid legend columns %d, font size %g or marker scale %g",
+			lo.columns, lo.fontSize.Points(), lo.markerScale)
+	}
+	return lo, nil
+}
+
+// legend laid out in rows and columns, ready to be drawn
+type legendLayout struct {
+	legendBox
+	text      draw.TextStyle
+	rows      int
+	colWidths []vg.Length
+	rowHeight vg.Length
+	thumb     vg.Length // width of the thumbnails
+	em        vg.Length // space between the thumbnails and the texts, and around the entries
+	size      vg.Point  // size of the whole legend box
+}
+
+func newLegendLayout(lb legendBox) *legendLayout {
+	if len(lb.entries) == 0 {
+		return nil
+	}
+	if lb.options.columns == 0 {
+		lb.options = defaultLegendOptions()
+	}
+
+	base := plot.NewLegend()
+	l := &legendLayout{
+		legendBox: lb,
+		text:      base.TextStyle,
+		thumb:     base.ThumbnailWidth,
+	}
+	l.text.Font.Size = lb.options.fontSize
+	l.text.XAlign, l.text.YAlign = draw.XLeft, draw.YCenter
+	l.em = l.text.Width("M")
+
+	cols := lb.options.columns
+	if cols > len(lb.entries) {
+		cols = len(lb.entries)
+	}
+	l.rows = (len(lb.entries) + cols - 1) / cols
+	l.colWidths = make([]vg.Length, cols)
+	for i, e := range lb.entries {
+		col := i / l.rows
+		if w := l.thumb + l.em/2 + l.text.Width(e.name); w > l.colWidths[col] {
+			l.colWidths[col] = w
+		}
+		if h := l.text.Height(e.name); h > l.rowHeight {
+			l.rowHeight = h
+		}
+	}
+
+	// the entries and the title inside a margin of half an em
+	for _, w := range l.colWidths {
+		l.size.X += w
+	}
+	l.size.X += vg.Length(cols-1) * l.em
+	l.size.Y = vg.Length(l.rows) * l.rowHeight
+	if lb.options.title != "" {
+		if w := l.text.Width(lb.options.title); w > l.size.X {
+			l.size.X = w
+		}
+		l.size.Y += l.text.Height(lb.options.title)
+	}
+	l.size.X += l.em
+	l.size.Y += l.em
+	return l
+}
+
+// draw the legend with its lower left corner at the given point
+func (l *legendLayout) draw(c vg.Canvas, at vg.Point) {
+	box := vg.Rectangle{Min: at, Max: at.Add(l.size)}
+	if l.options.background != nil {
+		c.SetColor(withAlpha(l.options.background, l.options.alpha))
+		c.Fill(box.Path())
+	}
+	if l.options.frame {
+		c.SetColor(Black)
+		c.SetLineWidth(vg.Points(0.5))
+		c.SetLineDash(nil, 0)
+		c.Stroke(box.Path())
+	}
+
+	dc := draw.Canvas{Canvas: c, Rectangle: box}
+	top := box.Max.Y - l.em/2
+	if l.options.title != "" {
+		sty := l.text
+		sty.XAlign, sty.YAlign = draw.XCenter, draw.YTop
+		dc.FillText(sty, vg.Point{X: (box.Min.X + box.Max.X) / 2, Y: top}, l.options.title)
+		top -= l.text.Height(l.options.title)
+	}
+
+	x := box.Min.X + l.em/2
+	for col, w := range l.colWidths {
+		for row := 0; row < l.rows; row++ {
+			i := col*l.rows + row
+			if i >= len(l.entries) {
+				break
+			}
+			e := l.entries[i]
+			y := top - vg.Length(row+1)*l.rowHeight
+			icon := &draw.Canvas{
+				Canvas:    c,
+				Rectangle: vg.Rectangle{Min: vg.Point{X: x, Y: y}, Max: vg.Point{X: x + l.thumb, Y: y + l.rowHeight}},
+			}
+			var end func()
+			if e.series.mark != nil {
+				end = e.series.mark(c, true)
+			}
+			for _, t := range e.series.thumbs {
+				scaledThumbnail(t, l.options.markerScale).Thumbnail(icon)
+			}
+			dc.FillText(l.text, vg.Point{X: x + l.thumb + l.em/2, Y: y + l.rowHeight/2}, e.name)
+			if end != nil {
+				end()
+			}
+		}
+		x += w + l.em
+	}
+}
+
+// thumbnail with its markers scaled
+func scaledThumbnail(t plot.Thumbnailer, scale float64) plot.Thumbnailer {
+	if scale == 1 {
+		return t
+	}
+	if s, ok := t.(*plotter.Scatter); ok {
+		scaled := *s
+		scaled.GlyphStyle.Radius *= vg.Length(scale)
+		return &scaled
+	}
+	return t
+}
+
+// series of the plot and its twins given a label, in the order they are drawn
+func (plt *plotParameters) labelledEntries() []legendEntry {
+	var entries []legendEntry
+	for _, p := range plt.withTwins() {
+		for _, s := range p.series {
+			if s.label != "" && !s.hidden {
+				entries = append(entries, legendEntry{name: s.label, series: s})
+			}
+		}
+	}
+	return entries
+}
+
+// entries shown in the legend of the plot
+func (plt *plotParameters) legendEntries() []legendEntry {
+	var entries []legendEntry
+	for _, e := range plt.legendBox.entries {
+		if !e.series.hidden {
+			entries = append(entries, e)
+		}
+	}
+	if plt.legendBox.labelled {
+		entries = append(entries, plt.labelledEntries()...)
+	}
+	return entries
+}
+
+// thumbnail of a line across the legend entry
+type lineThumb struct {
+	draw.LineStyle
+}
+
+func (t lineThumb) Thumbnail(c *draw.Canvas) {
+	y := c.Center().Y
+	c.StrokeLine2(t.LineStyle, c.Min.X, y, c.Max.X, y)
+}
+
+// thumbnail of a box filled with a color
+type fillThumb struct {
+	color color.Color
+}
+
+func (t fillThumb) Thumbnail(c *draw.Canvas) {
+	c.SetColor(t.color)
+	c.Fill(c.Rectangle.Path())
+}
+
+// lower left corner of the legend inside the area, at one of the nine locations
+func (l *legendLayout) corner(area vg.Rectangle, location legendLocType) vg.Point {
+	f := legendLocations[location]
+	free := area.Size().Sub(l.size).Sub(vg.Point{X: 2 * legendInset, Y: 2 * legendInset})
+	return vg.Point{
+		X: area.Min.X + legendInset + vg.Length(f[0])*free.X,
+		Y: area.Min.Y + legendInset + vg.Length(f[1])*free.Y,
+	}
+}
+
+// lower left corner of the legend inside the area at the location covering the fewest points
+func (l *legendLayout) bestCorner(area vg.Rectangle, points []vg.Point) vg.Point {
+	best, fewest := l.corner(area, bestLocations[0]), len(points)+1
+	for _, location := range bestLocations {
+		at := l.corner(area, location)
+		box := vg.Rectangle{Min: at, Max: at.Add(l.size)}
+		n := 0
+		for _, pt := range points {
+			if pt.X >= box.Min.X && pt.X <= box.Max.X && pt.Y >= box.Min.Y && pt.Y <= box.Max.Y {
+				n++
+			}
+		}
+		if n < fewest {
+			best, fewest = at, n
+		}
+	}
+	return best
+}
+
+// reserve the space of a legend outside the axes, returns where to draw it
+// once the data canvas is known
+func (l *legendLayout) reserve(c *draw.Canvas) (place func(dc draw.Canvas) vg.Point) {
+	switch l.options.location {
+	case OutsideRight:
+		c.Max.X -= l.size.X + legendInset
+		right := c.Max.X + legendInset
+		return func(dc draw.Canvas) vg.Point {
+			return vg.Point{X: right, Y: dc.Max.Y - l.size.Y}
+		}
+	case OutsideBelow:
+		bottom := c.Min.Y
+		c.Min.Y += l.size.Y + legendInset
+		return func(dc draw.Canvas) vg.Point {
+			return vg.Point{X: dc.Center().X - l.size.X/2, Y: bottom}
+		}
+	}
+	return nil
+}
+
+// positions of the data of the plot and its twins on the canvas, avoided by the best legend location
+func (plt *plotParameters) dataPoints(dc draw.Canvas) []vg.Point {
+	var points []vg.Point
+	for _, p := range plt.withTwins() {
+		trX, trY := p.plot.Transforms(&dc)
+		for _, pl := range p.shown() {
+			xys, ok := pl.(plotter.XYer)
+			if !ok {
+				continue
+			}
+			for i := 0; i < xys.Len(); i++ {
+				x, y := xys.XY(i)
+				pt := vg.Point{X: trX(x), Y: trY(y)}
+				// points along the segments of lines
+				if i > 0 {
+					if _, line := pl.(*plotter.Line); line {
+						x0, y0 := xys.XY(i - 1)
+						prev := vg.Point{X: trX(x0), Y: trY(y0)}
+						for k := 1; k < 4; k++ {
+							points = append(points, prev.Add(pt.Sub(prev).Scale(vg.Length(k)/4)))
+						}
+					}
+				}
+				points = append(points, pt)
+			}
+		}
+	}
+	return points
+}
+
+// draw the legend in the area, with place giving the position of outside legends
+// and points the data avoided by the best location
+func (l *legendLayout) drawIn(c vg.Canvas, area draw.Canvas, place func(draw.Canvas) vg.Point, points func() []vg.Point) {
+	switch {
+	case place != nil:
+		l.draw(c, place(area))
+	case l.options.location == Best:
+		l.draw(c, l.bestCorner(area.Rectangle, points()))
+	default:
+		l.draw(c, l.corner(area.Rectangle, l.options.location))
+	}
+}
@@ -54,6 +54,8 @@ type lineOptions struct {
 	params
 	colorIndex int
 	usedColors map[color.Color]bool
+	userColors map[color.Color]bool // colors given by the options, skipped by the automatic colors
+	lastColor  color.Color          // color of the last line, shared by its bands
 }
 
 type params struct {
@@ -63,12 +65,21 @@ type params struct {
 	marker        draw.GlyphDrawer
 	markerSize    font.Length
 	markerSpacing int
+	rolling       int // number of points kept by a line fed with Append
+	label         string
+}
+
+// name of the line in the legend
+func WithLabel(label string) func(*lineOptions) {
+	return func(lo *lineOptions) {
+		lo.label = label
+	}
 }
 
 func WithLineColor(color colorType) func(*lineOptions) {
 	return func(lo *lineOptions) {
 		lo.color = color
-		lo.usedColors[color] = true
+		lo.useColor(color)
 	}
 }
 
@@ -96,22 +107,56 @@ func WithMarkerSize(size float64) func(*lineOptions) {
 	}
 }
 
+// draw a marker on every spacing-th point, spacing must be at least one
 func WithMarkerSpacing(spacing int) func(*lineOptions) {
 	return func(lo *lineOptions) {
 		lo.markerSpacing = spacing
 	}
 }
 
-// get the next color in the sequence
+// keep only the last n points of the line, dropping the oldest
+// as new points are appended
+func WithRollingWindow(n int) func(*lineOptions) {
+	return func(lo *lineOptions) {
+		lo.rolling = n
+	}
+}
+
+// get the next color in the sequence, starting the palette again
+// once all of its colors are used
 func (plt *plotParameters) nextColor() color.Color {
-	for {
-		c := colors[plt.lineOptions.colorIndex]
-		plt.lineOptions.colorIndex = (plt.lineOptions.colorIndex + 1) % len(colors)
-		if !plt.lineOptions.usedColors[c] {
-			plt.lineOptions.usedColors[c] = true
-			return c
+	lo := &plt.lineOptions
+	for pass := 0; pass < 2; pass++ {
+		for range colors {
+			c := colors[lo.colorIndex]
+			lo.colorIndex = (lo.colorIndex + 1) % len(colors)
+			if !lo.usedColors[c] {
+				lo.usedColors[c] = true
+				return c
+			}
+		}
+
+		// the palette starts again, still without the colors given by the user
+		lo.usedColors = make(map[color.Color]bool)
+		for c := range lo.userColors {
+			lo.usedColors[c] = true
 		}
+		lo.colorIndex = 0
+	}
+
+	// all the colors of the palette were given by the user
+	c := colors[lo.colorIndex]
+	lo.colorIndex = (lo.colorIndex + 1) % len(colors)
+	return c
+}
+
+// mark a color given by the user as used, also after the palette starts again
+func (lo *lineOptions) useColor(c color.Color) {
+	lo.usedColors[c] = true
+	if lo.userColors == nil {
+		lo.userColors = make(map[color.Color]bool)
 	}
+	lo.userColors[c] = true
 }
 
 // add markers to line plotter
@@ -0,0 +1,45 @@
+package plotter
+
+import "testing"
+
+func TestMarkerSpacing(t *testing.T) {
+	x, y := []float64{1, 2, 3, 4, 5}, []float64{1, 4, 9, 16, 25}
+
+	p := NewPlot()
+	l := p.Plot(x, y, WithMarker(Circle), WithMarkerSpacing(2))
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(l.(*lineArtist).markers.XYs); n != 3 {
+		t.Fatalf("%d markers, want 3", n)
+	}
+
+	for _, spacing := range []int{0, -2} {
+		p := NewPlot()
+		p.Plot(x, y, WithMarker(Circle), WithMarkerSpacing(spacing))
+		if p.Err() == nil {
+			t.Errorf("no error for a marker spacing of %d", spacing)
+		}
+
+		p = NewPlot()
+		p.LivePlot(WithMarker(Circle), WithMarkerSpacing(spacing)).Append(1, 2)
+		if p.Err() == nil {
+			t.Errorf("no error for a marker spacing of %d of a live line", spacing)
+		}
+	}
+}
+
+func TestNextColorKeepsUserColors(t *testing.T) {
+	p := NewPlot().(*plotParameters)
+	x, y := []float64{1, 2}, []float64{1, 2}
+	user := colors[1]
+	p.Plot(x, y, WithLineColor(user))
+
+	// automatic colors over more than two turns of the palette
+	for i := 0; i < 2*len(colors)+1; i++ {
+		l := p.Plot(x, y).(*lineArtist)
+		if l.line.Color == user {
+			t.Fatalf("line %d takes the color given to the first line", i)
+		}
+	}
+}
@@ -2,34 +2,78 @@ package plotter
 
 import (
 	"image/color"
+	"math"
+	"sync"
 
 	"github.com/mazznoer/colorgrad"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/palette"
-	"gonum.org/v1/plot/vg/vgimg"
+	"gonum.org/v1/plot/vg"
 )
 
 type plotParameters struct {
-	plot           *plot.Plot           // initialize new plot
-	lineOptions    lineOptions          // line plotter options
-	contourOptions contourOptions       // contour plotter options
-	scatterOptions scatterOptions       // scatter plotter options
-	legends        [][]plot.Thumbnailer // legend plotter config
-	figSize        figSize              // xwidth and ywidth of the saved figure
-	figure         vgimg.PngCanvas      // figure to plot and save
-	colorBar       colorBar             // show colorbar with gradient
+	plot           *plot.Plot        // initialize new plot
+	lineOptions    lineOptions       // line plotter options
+	contourOptions contourOptions    // contour plotter options
+	scatterOptions scatterOptions    // scatter plotter options
+	bars           []*barSeries      // bar charts of the plot
+	hists          []*histogram      // histograms of the plot
+	series         []*series         // plotted series, with their plotters and legend thumbnails
+	legendBox      legendBox         // named legend entries and legend style
+	figSize        figSize           // xwidth and ywidth of the saved figure
+	figure         vg.CanvasWriterTo // figure to plot and save
+	format         formatType        // format of the drawn figure
+	colorBar       colorBar          // show colorbar with gradient
+	xScale, yScale scaleType         // scales of the axes
+	xTicks, yTicks *tickOptions      // ticks of the axes, nil for the automatic ticks
+	grid           *gridLines        // grid lines at the ticks, nil for no grid
+	xLimits        bool              // x-axis limits given by XLim
+	yLimits        bool              // y-axis limits given by YLim
+	twinX, twinY   *plotParameters   // twins sharing the x-axis or the y-axis
+	parent         *plotParameters   // plot whose axis is shared by this twin
+	err            error             // first error found while building the plot
+	dirty          bool              // figure must be drawn again
+	stale          bool              // axis ranges must be fitted again to the shown series
+	view           drawnView         // data area and axis ranges of the last drawing
+	onPick         func(int, int)    // called with the series and the index of a clicked point
+	mu             *sync.Mutex       // lock of the figure, shared by its twins and subplots
 }
 
 type subplotParameters struct {
 	rows     int
 	cols     int
-	subplots [][]*plot.Plot  // plots for subplot
-	figSize  figSize         // xwidth and ywidth of the saved figure
-	figure   vgimg.PngCanvas // figure to plot and savev
+	subplots [][]*plotParameters // plots for subplot
+	legend   *legendOptions      // figure legend with the entries of all the subplots
+	figSize  figSize             // xwidth and ywidth of the saved figure
+	figure   vg.CanvasWriterTo   // figure to plot and savev
+	format   formatType          // format of the drawn figure
+	err      error               // first error found while building the subplot
+	dirty    bool                // figure must be drawn again
+	mu       *sync.Mutex         // lock of the figure, shared by its subplots
 }
 
-type figSize struct{ xwidth, ywidth int }
+type figSize struct {
+	xwidth, ywidth   vg.Length // size of the figure
+	xpixels, ypixels int       // size in pixels, resolved with the dpi when drawing
+	dpi              int       // resolution of raster figures
+}
+
+// size of the figure, converting a size in pixels with the figure dpi
+func (fs figSize) size() (xwidth, ywidth vg.Length) {
+	if fs.xpixels > 0 && fs.ypixels > 0 {
+		return vg.Length(fs.xpixels) / vg.Length(fs.dpi) * vg.Inch,
+			vg.Length(fs.ypixels) / vg.Length(fs.dpi) * vg.Inch
+	}
+	return fs.xwidth, fs.ywidth
+}
+
+// size of the figure in pixels at the figure dpi
+func (fs figSize) pixels() (xpixels, ypixels int) {
+	xwidth, ywidth := fs.size()
+	dpi := float64(fs.dpi)
+	return int(math.Ceil(xwidth.Dots(dpi))), int(math.Ceil(ywidth.Dots(dpi)))
+}
 
 // struct that defines methods to match the GridXYZ interface defined in gonum plot library
 // used in heatmap and contour plots
@@ -68,6 +112,18 @@ func (g *colorsGradient) SetMax(v float64)   { g.max = v }
 func (g *colorsGradient) SetMin(v float64)   { g.min = v }
 func (g *colorsGradient) SetAlpha(a float64) {}
 
+// color with its alpha channel scaled by alpha, between 0 and 1
+func withAlpha(c color.Color, alpha float64) color.Color {
+	alpha = math.Max(0, math.Min(1, alpha))
+	r, g, b, a := c.RGBA()
+	return color.NRGBA64{
+		R: uint16(float64(r) * 0xffff / math.Max(float64(a), 1)),
+		G: uint16(float64(g) * 0xffff / math.Max(float64(a), 1)),
+		B: uint16(float64(b) * 0xffff / math.Max(float64(a), 1)),
+		A: uint16(float64(a) * alpha),
+	}
+}
+
 // generate linearly spaced slice of float64
 func Linspace(start, stop float64, num int) []float64 {
 	var step float64
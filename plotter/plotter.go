@@ -1,22 +1,17 @@
 package plotter
 
 import (
+	"fmt"
 	"image"
 	"image/color"
-	"log"
+	"io"
+	"math"
 	"os"
+	"sync"
 
-	"gioui.org/app"
-	"gioui.org/io/system"
-	"gioui.org/layout"
-	"gioui.org/op"
-	"gioui.org/op/paint"
-	"gioui.org/unit"
 	"github.com/mazznoer/colorgrad"
-	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/plot"
-	"gonum.org/v1/plot/font"
 	"gonum.org/v1/plot/palette"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/vg"
@@ -25,48 +20,115 @@ import (
 )
 
 type PlotterInterface interface {
-	Plot(x, y []float64, options ...func(*lineOptions))
-	Contour(x, y, z *mat.Dense, options ...func(*contourOptions))
-	ContourF(x, y, z *mat.Dense, options ...func(*contourOptions))
-	Scatter(x, y, z []float64, options ...func(*scatterOptions))
-	ImShow(x []*mat.Dense)
+	Plot(x, y []float64, options ...func(*lineOptions)) Line
+	LivePlot(options ...func(*lineOptions)) Line
+	Contour(x, y, z *mat.Dense, options ...func(*contourOptions)) ContourSet
+	ContourF(x, y, z *mat.Dense, options ...func(*contourOptions)) ContourSet
+	Scatter(x, y, z []float64, options ...func(*scatterOptions)) Collection
+	Bar(labels []string, heights []float64, options ...func(*barOptions))
+	BarH(labels []string, heights []float64, options ...func(*barOptions))
+	Hist(data []float64, options ...func(*histOptions)) (counts, edges []float64)
+	BoxPlot(groups [][]float64, labels []string, options ...func(*boxOptions))
+	Violin(groups [][]float64, labels []string, options ...func(*violinOptions))
+	ErrorBar(x, y, xerr, yerr []float64, options ...func(*errorBarOptions))
+	FillBetween(x, y1, y2 []float64, options ...func(*fillOptions))
+	ImShow(x []*mat.Dense) AxesImage
 	Title(str string)
 	XLabel(xlabel string)
 	YLabel(ylabel string)
 	Legend(str ...string)
+	LegendStyle(options ...func(*legendOptions))
 	XLim(xmin, xmax float64)
 	YLim(ymin, ymax float64)
-	Grid()
+	Grid(options ...func(*gridOptions))
+	XScale(scale scaleType)
+	YScale(scale scaleType)
+	SemiLogX(x, y []float64, options ...func(*lineOptions)) Line
+	SemiLogY(x, y []float64, options ...func(*lineOptions)) Line
+	LogLog(x, y []float64, options ...func(*lineOptions)) Line
+	XTicks(positions []float64, labels []string, options ...func(*tickOptions))
+	YTicks(positions []float64, labels []string, options ...func(*tickOptions))
+	OnPick(pick func(series, index int))
+	TwinX() PlotterInterface
+	TwinY() PlotterInterface
+	Clear()
+	Reset()
+	Err() error
 }
 
 type Plot interface {
 	PlotterInterface
 	FigSize(xwidth, ywidth int)
-	Save(name string)
-	Show()
+	FigSizeInches(xwidth, ywidth float64)
+	FigSizePixels(xwidth, ywidth int)
+	FigDPI(dpi int)
+	Save(name string, options ...func(*saveOptions)) error
+	WriteTo(w io.Writer, format formatType) (int64, error)
+	Image() image.Image
+	Show(options ...func(*viewerOptions)) error
+	ShowAsync(options ...func(*viewerOptions)) *Window
+	ShowLater()
+	Viewer(options ...func(*viewerOptions)) *Viewer
+	ShowTerminal(options ...func(*terminalOptions)) error
+	SaveHTML(file string) error
+	RenderText(w io.Writer, cols, rows int, options ...func(*textOptions)) error
+	String() string
 }
 
 func NewPlot() Plot {
-	return &plotParameters{
+	plt := &plotParameters{
 		plot: plot.New(),
 		lineOptions: lineOptions{
 			usedColors: make(map[color.Color]bool),
 		},
 		figSize: figSize{
-			xwidth: 10,
-			ywidth: 10,
-		},
-		figure: vgimg.PngCanvas{
-			Canvas: &vgimg.Canvas{},
+			dpi:    vgimg.DefaultDPI,
+			xwidth: 10 * vg.Centimeter,
+			ywidth: 10 * vg.Centimeter,
 		},
+		mu: new(sync.Mutex),
 	}
+	return plt
 }
 
 // parameters to lines plots
-func (plt *plotParameters) Plot(x, y []float64, options ...func(*lineOptions)) {
-	var thumbs []plot.Thumbnailer
-	var plotters []plot.Plotter
+func (plt *plotParameters) Plot(x, y []float64, options ...func(*lineOptions)) Line {
+	defer plt.lock()()
+	plt.dirty = true
+
+	if err := plt.setLineOptions(options...); err != nil {
+		plt.setErr(err)
+		return &lineArtist{}
+	}
+	if err := checkXY(x, y); err != nil {
+		plt.setErr(err)
+		return &lineArtist{}
+	}
+
+	// various plots to the figure
+	pts := make(plotter.XYs, len(x))
+	for j := range pts {
+		pts[j].X = x[j]
+		pts[j].Y = y[j]
+	}
+	return plt.addLine(pts)
+}
+
+// empty line fed later with Append or Stream, from any goroutine,
+// while the figure is shown
+func (plt *plotParameters) LivePlot(options ...func(*lineOptions)) Line {
+	defer plt.lock()()
+	plt.dirty = true
+
+	if err := plt.setLineOptions(options...); err != nil {
+		plt.setErr(err)
+		return &lineArtist{}
+	}
+	return plt.addLine(plotter.XYs{})
+}
 
+// default line options with the additional options applied
+func (plt *plotParameters) setLineOptions(options ...func(*lineOptions)) error {
 	// default options
 	plt.lineOptions.params = params{
 		lineStyle:     Solid,
@@ -79,21 +141,34 @@ func (plt *plotParameters) Plot(x, y []float64, options ...func(*lineOptions)) {
 	for _, option := range options {
 		option(&plt.lineOptions)
 	}
+	if plt.lineOptions.markerSpacing < 1 {
+		return fmt.Errorf("plotter: invalid marker spacing %d", plt.lineOptions.markerSpacing)
+	}
+	return nil
+}
+
+// add a line through the points with the current line options
+func (plt *plotParameters) addLine(pts plotter.XYs) Line {
+	var thumbs []plot.Thumbnailer
+	var plotters []plot.Plotter
 
 	// automatic color assignment
 	if plt.lineOptions.color == nil {
 		plt.lineOptions.color = plt.nextColor()
 	}
+	plt.lineOptions.lastColor = plt.lineOptions.color
 
-	// various plots to the figure
-	pts := make(plotter.XYs, len(x))
-	for j := range pts {
-		pts[j].X = x[j]
-		pts[j].Y = y[j]
+	// only the last points of a rolling window
+	if n := plt.lineOptions.rolling; n > 0 && len(pts) > n {
+		pts = pts[len(pts)-n:]
 	}
 
 	// make a line plotter and set its style.
-	line, _ := plotter.NewLine(pts)
+	line, err := plotter.NewLine(pts)
+	if err != nil {
+		plt.setErr(err)
+		return &lineArtist{}
+	}
 	line.Color = plt.lineOptions.color
 	line.LineStyle.Width = plt.lineOptions.lineWidth
 	line.LineStyle.Dashes = plt.lineOptions.lineStyle
@@ -102,22 +177,30 @@ func (plt *plotParameters) Plot(x, y []float64, options ...func(*lineOptions)) {
 	plotters = append(plotters, line)
 
 	// add markers to line plotter
+	var markers *plotter.Scatter
 	if plt.lineOptions.marker != nil {
-		scatter := plt.addMarkers(pts)
+		markers = plt.addMarkers(pts)
 
-		thumbs = append(thumbs, scatter)
-		plotters = append(plotters, scatter)
+		thumbs = append(thumbs, markers)
+		plotters = append(plotters, markers)
 	}
 
-	// thumbs for the legends
-	plt.legends = append(plt.legends, thumbs)
-
 	// add the plotters to the plot
-	plt.plot.Add(plotters...)
+	s := plt.add(plt.lineOptions.label, thumbs, plotters...)
+	return &lineArtist{
+		artist:  artist{plt: plt, series: s},
+		line:    line,
+		markers: markers,
+		spacing: plt.lineOptions.markerSpacing,
+		rolling: plt.lineOptions.rolling,
+	}
 }
 
 // parameters to contour plot
-func (plt *plotParameters) Contour(x, y, z *mat.Dense, options ...func(*contourOptions)) {
+func (plt *plotParameters) Contour(x, y, z *mat.Dense, options ...func(*contourOptions)) ContourSet {
+	defer plt.lock()()
+	plt.dirty = true
+
 	// default options
 	plt.contourOptions = contourOptions{
 		nLevels: 10,
@@ -131,6 +214,14 @@ func (plt *plotParameters) Contour(x, y, z *mat.Dense, options ...func(*contourO
 	for _, option := range options {
 		option(&plt.contourOptions)
 	}
+	if err := checkGrid(x, y, z); err != nil {
+		plt.setErr(err)
+		return &contourArtist{}
+	}
+	if plt.contourOptions.nLevels < 1 {
+		plt.setErr(fmt.Errorf("plotter: invalid number of contour levels %d", plt.contourOptions.nLevels))
+		return &contourArtist{}
+	}
 	plt.colorBar = plt.contourOptions.colorBar
 
 	// prepare data to plot
@@ -150,18 +241,30 @@ func (plt *plotParameters) Contour(x, y, z *mat.Dense, options ...func(*contourO
 		Dashes: plt.contourOptions.lineSettings.style,
 	}}
 
+	// thumbs for the legends, with the middle color of the levels
+	thumb := lineThumb{c.LineStyles[0]}
+	if p != nil {
+		if colors := p.Colors(); len(colors) > 0 {
+			thumb.Color = colors[len(colors)/2]
+		}
+	}
+
 	// add the plotters to the plot
-	plt.plot.Add(c)
+	s := plt.add(plt.contourOptions.label, []plot.Thumbnailer{thumb}, c)
 
 	if plt.colorBar.show {
 		// get min and max values
 		plt.colorBar.min = c.Min
 		plt.colorBar.max = c.Max
 	}
+	return &contourArtist{artist: artist{plt: plt, series: s}, lines: c, nLevels: plt.contourOptions.nLevels}
 }
 
 // parameters to contourf plot
-func (plt *plotParameters) ContourF(x, y, z *mat.Dense, options ...func(*contourOptions)) {
+func (plt *plotParameters) ContourF(x, y, z *mat.Dense, options ...func(*contourOptions)) ContourSet {
+	defer plt.lock()()
+	plt.dirty = true
+
 	// default options
 	plt.contourOptions = contourOptions{
 		nLevels:  10,
@@ -179,6 +282,14 @@ func (plt *plotParameters) ContourF(x, y, z *mat.Dense, options ...func(*contour
 	for _, option := range options {
 		option(&plt.contourOptions)
 	}
+	if err := checkGrid(x, y, z); err != nil {
+		plt.setErr(err)
+		return &contourArtist{}
+	}
+	if plt.contourOptions.nLevels < 1 {
+		plt.setErr(fmt.Errorf("plotter: invalid number of contour levels %d", plt.contourOptions.nLevels))
+		return &contourArtist{}
+	}
 	plt.colorBar = plt.contourOptions.colorBar
 
 	// prepare data to plot
@@ -189,8 +300,11 @@ func (plt *plotParameters) ContourF(x, y, z *mat.Dense, options ...func(*contour
 	raster := plotter.NewHeatMap(m, &p)
 	raster.Rasterized = true
 
-	// add the plotters to the plot
-	plt.plot.Add(raster)
+	// thumbs for the legends, with the middle color of the levels
+	thumb := fillThumb{Black}
+	if len(p.colorList) > 0 {
+		thumb.color = p.colorList[len(p.colorList)/2]
+	}
 
 	if plt.colorBar.show {
 		// get min and max values
@@ -198,23 +312,30 @@ func (plt *plotParameters) ContourF(x, y, z *mat.Dense, options ...func(*contour
 		plt.colorBar.max = raster.Max
 	}
 
+	var c *plotter.Contour
+	plotters := []plot.Plotter{raster}
 	if plt.contourOptions.lineSettings.show {
 		// add contour lines to contourf
 		levels := Linspace(mat.Min(z), mat.Max(z), plt.contourOptions.nLevels)
-		c := plotter.NewContour(m, levels, nil)
+		c = plotter.NewContour(m, levels, nil)
 		c.LineStyles = []draw.LineStyle{{
 			Color:  Black,
 			Width:  plt.contourOptions.lineSettings.width,
 			Dashes: plt.contourOptions.lineSettings.style,
 		}}
-
-		// add the plotters to the plot
-		plt.plot.Add(c)
+		plotters = append(plotters, c)
 	}
+
+	// add the plotters to the plot
+	s := plt.add(plt.contourOptions.label, []plot.Thumbnailer{thumb}, plotters...)
+	return &contourArtist{artist: artist{plt: plt, series: s}, fill: raster, lines: c, nLevels: plt.contourOptions.nLevels}
 }
 
 // parameters to scatter plot
-func (plt *plotParameters) Scatter(x, y, z []float64, options ...func(*scatterOptions)) {
+func (plt *plotParameters) Scatter(x, y, z []float64, options ...func(*scatterOptions)) Collection {
+	defer plt.lock()()
+	plt.dirty = true
+
 	// default options
 	plt.scatterOptions = scatterOptions{
 		color:      Blue,
@@ -226,59 +347,94 @@ func (plt *plotParameters) Scatter(x, y, z []float64, options ...func(*scatterOp
 	for _, option := range options {
 		option(&plt.scatterOptions)
 	}
+
+	// make a scatter plotter
+	sc, err := newScatter(x, y, z, plt.scatterOptions)
+	if err != nil {
+		plt.setErr(err)
+		return &scatterArtist{}
+	}
 	plt.colorBar = plt.scatterOptions.colorBar
 
+	// add the plotters to the plot
+	s := plt.add(plt.scatterOptions.label, []plot.Thumbnailer{sc}, sc)
+
+	if plt.colorBar.show {
+		// get min and max values
+		plt.colorBar.min, plt.colorBar.max = plt.scatterOptions.zRange(z)
+	}
+	return &scatterArtist{artist: artist{plt: plt, series: s}, scatter: sc, options: plt.scatterOptions}
+}
+
+// scatter plotter of the points, colored by z with the gradient of the options
+func newScatter(x, y, z []float64, so scatterOptions) (*plotter.Scatter, error) {
+	if err := checkXY(x, y); err != nil {
+		return nil, err
+	}
+	if len(z) != 0 && len(z) != len(x) {
+		return nil, fmt.Errorf("plotter: len(x) = %d and len(z) = %d mismatch", len(x), len(z))
+	}
+	// the gradient and the colorbar color the points by z
+	if len(z) == 0 && (so.gradient != (colorgrad.Gradient{}) || so.colorBar.show) {
+		return nil, ErrEmptyData
+	}
+
 	// prepare data to plot
-	var xys plotter.XYer
-	if len(z) == 0 {
-		pts := make(plotter.XYs, len(x))
-		for i := range pts {
-			pts[i].X = x[i]
-			pts[i].Y = y[i]
-		}
-		xys = pts
-	} else {
-		pts := make(plotter.XYZs, len(x))
-		for i := range pts {
-			pts[i].X = x[i]
-			pts[i].Y = y[i]
-			pts[i].Z = z[i]
-		}
-		xys = pts
+	pts := make(plotter.XYs, len(x))
+	for i := range pts {
+		pts[i].X = x[i]
+		pts[i].Y = y[i]
 	}
 
-	// add colormap and make a scatter plotter
-	sc, err := plotter.NewScatter(xys)
+	sc, err := plotter.NewScatter(pts)
 	if err != nil {
-		log.Panic(err)
+		return nil, err
 	}
 	sc.GlyphStyle = draw.GlyphStyle{
-		Color:  plt.scatterOptions.color,
-		Radius: plt.scatterOptions.markerSize,
-		Shape:  plt.scatterOptions.marker,
+		Color:  so.color,
+		Radius: so.markerSize,
+		Shape:  so.marker,
 	}
 
-	if plt.scatterOptions.gradient != (colorgrad.Gradient{}) {
-		// specify style and color for individual points.
+	if so.gradient != (colorgrad.Gradient{}) {
+		// specify style and color for individual points, from their z
+		// value in the range mapped to the gradient
+		min, max := so.zRange(z)
+		colors := make([]color.Color, len(z))
+		for i, v := range z {
+			t := 0.5
+			if max > min {
+				t = math.Max(0, math.Min(1, (v-min)/(max-min)))
+			}
+			colors[i] = so.gradient.At(t).Clamped()
+		}
 		sc.GlyphStyleFunc = func(i int) draw.GlyphStyle {
-			colors := plt.scatterOptions.gradient.Colors(uint(len(z)))
-			return draw.GlyphStyle{Color: colors[i], Radius: plt.scatterOptions.markerSize,
-				Shape: plt.scatterOptions.marker}
+			return draw.GlyphStyle{Color: colors[i], Radius: so.markerSize, Shape: so.marker}
 		}
 	}
+	return sc, nil
+}
 
-	// add the plotters to the plot
-	plt.plot.Add(sc)
+// parameters to image plot
+func (plt *plotParameters) ImShow(x []*mat.Dense) AxesImage {
+	defer plt.lock()()
+	plt.dirty = true
 
-	if plt.colorBar.show {
-		// get min and max values
-		plt.colorBar.min = floats.Min(z)
-		plt.colorBar.max = floats.Max(z)
+	if err := checkImage(x); err != nil {
+		plt.setErr(err)
+		return &imageArtist{}
 	}
+
+	// add and make a image plotter
+	img := newImage(x)
+	s := plt.add("", nil, img)
+	rows, _ := x[0].Dims()
+	plt.plot.X.Max = float64(rows) + 0.02*float64(rows)
+	return &imageArtist{artist: artist{plt: plt, series: s}, image: img}
 }
 
-// parameters to image plot
-func (plt *plotParameters) ImShow(x []*mat.Dense) {
+// image plotter of one grayscale or three RGB channels
+func newImage(x []*mat.Dense) *plotter.Image {
 	// prepare data to plot
 	var img image.Image
 	rows, cols := x[0].Dims()
@@ -311,142 +467,473 @@ func (plt *plotParameters) ImShow(x []*mat.Dense) {
 		}
 		img = rgbImg
 	}
+	return plotter.NewImage(img, xmin, ymin, xmax, ymax)
+}
 
-	// add and make a image plotter
-	plt.plot.Add(plotter.NewImage(img, xmin, ymin, xmax, ymax))
-	plt.plot.X.Max = float64(rows) + 0.02*float64(rows)
+// draw the plot, its grid and its twins to the canvas
+func (plt *plotParameters) draw(c draw.Canvas) {
+	plots := plt.withTwins()
+	defer func() {
+		for _, p := range plots {
+			p.dirty = false
+		}
+	}()
+
+	// the background, the title and the legend are drawn here so that
+	// the grid, the twins and the legend go around the data
+	if plt.plot.BackgroundColor != nil {
+		c.SetColor(plt.plot.BackgroundColor)
+		c.Fill(c.Rectangle.Path())
+	}
+	title := plt.plot.Title
+	if title.Text != "" {
+		descent := title.TextStyle.FontExtents().Descent
+		c.FillText(title.TextStyle, vg.Point{X: c.Center().X, Y: c.Max.Y + descent}, title.Text)
+		c.Max.Y -= title.TextStyle.Rectangle(title.Text).Size().Y + title.Padding
+	}
+	background := plt.plot.BackgroundColor
+	plt.plot.BackgroundColor, plt.plot.Title.Text = nil, ""
+	defer func() {
+		plt.plot.BackgroundColor, plt.plot.Title.Text = background, title.Text
+	}()
+
+	// space for a legend outside the axes
+	legend := newLegendLayout(legendBox{entries: plt.legendEntries(), options: plt.legendBox.options})
+	var place func(draw.Canvas) vg.Point
+	if legend != nil {
+		place = legend.reserve(&c)
+	}
+
+	// space for the axes of the twins
+	if twin := plt.twinX; twin != nil {
+		sanitizeRange(&twin.plot.Y)
+		c.Max.X -= rightAxisWidth(twin.plot.Y)
+	}
+	if twin := plt.twinY; twin != nil {
+		sanitizeRange(&twin.plot.X)
+		c.Max.Y -= topAxisHeight(twin.plot.X)
+	}
+
+	dc := plt.plot.DataCanvas(c)
+	if twin := plt.twinX; twin != nil {
+		twin.plot.X = plt.plot.X
+	}
+	if twin := plt.twinY; twin != nil {
+		twin.plot.Y = plt.plot.Y
+	}
+	for _, p := range plots {
+		p.sizeBars(dc)
+	}
+
+	// the drawn area and ranges, zoomed and panned by the viewer
+	for _, p := range plots {
+		p.view = drawnView{
+			area: dc.Rectangle,
+			x:    [2]float64{p.plot.X.Min, p.plot.X.Max},
+			y:    [2]float64{p.plot.Y.Min, p.plot.Y.Max},
+		}
+	}
+
+	drawGrids := func(above bool) {
+		for _, p := range plots {
+			if p.grid != nil && p.grid.above == above {
+				p.grid.Plot(dc, p.plot)
+			}
+		}
+	}
+
+	drawGrids(false)
+	plt.plot.Draw(c)
+	if twin := plt.twinX; twin != nil {
+		for _, p := range twin.drawn() {
+			p.Plot(dc, twin.plot)
+		}
+		drawRightAxis(dc, twin.plot.Y)
+	}
+	if twin := plt.twinY; twin != nil {
+		for _, p := range twin.drawn() {
+			p.Plot(dc, twin.plot)
+		}
+		drawTopAxis(dc, twin.plot.X)
+	}
+	drawGrids(true)
+	if legend != nil {
+		legend.drawIn(c.Canvas, dc, place, func() []vg.Point { return plt.dataPoints(dc) })
+	}
 }
 
 // draw plot to a figure
-func (plt *plotParameters) DrawPlot() {
-	xwidth := font.Length(plt.figSize.xwidth) * vg.Centimeter
-	ywidth := font.Length(plt.figSize.ywidth) * vg.Centimeter
-
-	// new image canvas
-	img := vgimg.New(xwidth, ywidth)
-
-	// draw the plot
-	plt.plot.Draw(draw.Canvas{
-		Canvas: draw.New(img),
-		Rectangle: vg.Rectangle{
-			Min: vg.Point{X: 0, Y: 0},
-			Max: vg.Point{X: xwidth, Y: ywidth},
-		},
-	})
+func (plt *plotParameters) DrawPlot(format formatType) error {
+	xwidth, ywidth := plt.figSize.size()
 
-	// add colorbar to plot
+	// extra space to the colorbar, taken from the plot
+	// when the figure has an exact size in pixels
+	figWidth, figHeight := xwidth, ywidth
 	if plt.colorBar.show {
 		switch plt.colorBar.position {
 		case Vertical:
-			img = plt.drawVerticalColorBar(xwidth, ywidth)
+			figWidth += 1.35 * colorBarSpacing
 		case Horizontal:
-			img = plt.drawHorizontalColorBar(xwidth, ywidth)
+			figHeight += colorBarSpacing
+		}
+		if plt.figSize.xpixels > 0 {
+			xwidth -= figWidth - xwidth
+			ywidth -= figHeight - ywidth
+			figWidth, figHeight = plt.figSize.size()
 		}
 	}
 
-	plt.figure = vgimg.PngCanvas{Canvas: img}
-}
-
-// show plot in graphical window
-func (plt *plotParameters) Show() {
-	if plt.figure.Image() == nil {
-		plt.DrawPlot()
-	}
-
-	// graphical window creation
-	imgData := plt.figure.Image()
-	window := app.NewWindow(
-		app.Title("Plot Viewer"),
-		app.Size(unit.Dp(float32(imgData.Bounds().Dx())),
-			unit.Dp(float32(imgData.Bounds().Dy()))),
-	)
-
-	var ops op.Ops
-	for e := range window.Events() {
-		switch e := e.(type) {
-		case system.DestroyEvent:
-			return
-		case system.FrameEvent:
-			gtx := layout.NewContext(&ops, e)
-			layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-				imgOp := paint.NewImageOp(imgData)
-				imgOp.Add(gtx.Ops)
-				paint.PaintOp{}.Add(gtx.Ops)
-				return layout.Dimensions{Size: gtx.Constraints.Max}
-			})
-			e.Frame(gtx.Ops)
-		}
+	// new canvas for the output format
+	img, err := newCanvas(plt.figSize.dpi, figWidth, figHeight, format)
+	if err != nil {
+		return err
+	}
+
+	// draw the plot and add colorbar to plot
+	defer plt.prepare()()
+	switch {
+	case plt.colorBar.show && plt.colorBar.position == Vertical:
+		plt.drawVerticalColorBar(img, xwidth, ywidth)
+	case plt.colorBar.show && plt.colorBar.position == Horizontal:
+		plt.drawHorizontalColorBar(img, xwidth, ywidth)
+	default:
+		plt.draw(draw.Canvas{
+			Canvas: img,
+			Rectangle: vg.Rectangle{
+				Min: vg.Point{X: 0, Y: 0},
+				Max: vg.Point{X: xwidth, Y: ywidth},
+			},
+		})
+	}
+
+	plt.figure = img
+	plt.format = format
+	return nil
+}
+
+// show plot in an interactive graphical window and wait until it is closed,
+// from a goroutine other than the one running Main on macOS
+func (plt *plotParameters) Show(options ...func(*viewerOptions)) error {
+	return plt.ShowAsync(options...).Wait()
+}
+
+// show plot in an interactive graphical window without waiting for it to be closed
+func (plt *plotParameters) ShowAsync(options ...func(*viewerOptions)) *Window {
+	return showAsync(plt, options...)
+}
+
+// queue the plot to be shown with the other queued figures by ShowAll
+func (plt *plotParameters) ShowLater() {
+	addPending(plt)
+}
+
+// draw the plot inline in a terminal with graphics support, such as over SSH
+func (plt *plotParameters) ShowTerminal(options ...func(*terminalOptions)) error {
+	return showTerminal(plt, options...)
+}
+
+// interactive view of the plot, as shown by Show
+func (plt *plotParameters) Viewer(options ...func(*viewerOptions)) *Viewer {
+	return newViewer(plt, options...)
+}
+
+// figure drawn at an exact size in pixels until restore is called
+func (plt *plotParameters) pixelSize(width, height int) (restore func()) {
+	size := plt.figSize
+	plt.figSize.xpixels, plt.figSize.ypixels = width, height
+	plt.format = ""
+	return func() {
+		plt.figSize = size
+		plt.format = ""
 	}
-	app.Main()
 }
 
-// save the plot to an image file
-func (plt *plotParameters) Save(file string) {
-	// save the plot to a PNG file.
-	if plt.figure.Image() == nil {
-		plt.DrawPlot()
+// plots zoomed and panned by the viewer
+func (plt *plotParameters) views() []*plotParameters {
+	return []*plotParameters{plt}
+}
+
+// lock of the figure, held while it is drawn or changed by the handles and the viewer
+func (plt *plotParameters) locker() sync.Locker {
+	return plt.mu
+}
+
+// hold the lock of the figure until unlock is called
+func (plt *plotParameters) lock() (unlock func()) {
+	plt.mu.Lock()
+	return plt.mu.Unlock
+}
+
+func (plt *plotParameters) resolution() int {
+	return plt.figSize.dpi
+}
+
+func (plt *plotParameters) rasterSize() (xpixels, ypixels int) {
+	return plt.figSize.pixels()
+}
+
+// save the plot to a file in the format given by its extension
+func (plt *plotParameters) Save(file string, options ...func(*saveOptions)) error {
+	plt.mu.Lock()
+	defer plt.mu.Unlock()
+	return plt.save(file, options...)
+}
+
+func (plt *plotParameters) save(file string, options ...func(*saveOptions)) error {
+	if err := plt.firstErr(); err != nil {
+		return err
+	}
+
+	format, err := saveFormat(file, options...)
+	if err != nil {
+		return err
 	}
 
 	// save the image to a file
 	w, err := os.Create(file)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	defer w.Close()
 
-	if _, err := plt.figure.WriteTo(w); err != nil {
-		panic(err)
+	if _, err := plt.writeTo(w, format); err != nil {
+		w.Close()
+		return err
 	}
+	return w.Close()
+}
+
+// write the plot to w in the given format
+func (plt *plotParameters) WriteTo(w io.Writer, format formatType) (int64, error) {
+	plt.mu.Lock()
+	defer plt.mu.Unlock()
+	return plt.writeTo(w, format)
+}
+
+func (plt *plotParameters) writeTo(w io.Writer, format formatType) (int64, error) {
+	if err := plt.firstErr(); err != nil {
+		return 0, err
+	}
+
+	if plt.isDirty() || plt.format != format {
+		if err := plt.DrawPlot(format); err != nil {
+			return 0, err
+		}
+	}
+	return plt.figure.WriteTo(w)
+}
+
+// rendered plot as a raster image, nil if the plot has errors
+func (plt *plotParameters) Image() image.Image {
+	plt.mu.Lock()
+	defer plt.mu.Unlock()
+	return plt.image()
+}
+
+func (plt *plotParameters) image() image.Image {
+	if plt.firstErr() != nil {
+		return nil
+	}
+
+	if plt.isDirty() || plt.format != PNG {
+		if err := plt.DrawPlot(PNG); err != nil {
+			return nil
+		}
+	}
+	return plt.figure.(vgimg.PngCanvas).Image()
 }
 
 // size of the saved figure
 func (plt *plotParameters) FigSize(xwidth, ywidth int) {
-	plt.figSize.xwidth = xwidth
-	plt.figSize.ywidth = ywidth
+	defer plt.lock()()
+	plt.dirty = true
+
+	if xwidth <= 0 || ywidth <= 0 {
+		plt.setErr(fmt.Errorf("plotter: invalid figure size %dx%d cm", xwidth, ywidth))
+		return
+	}
+	plt.figSize.xwidth = vg.Length(xwidth) * vg.Centimeter
+	plt.figSize.ywidth = vg.Length(ywidth) * vg.Centimeter
+	plt.figSize.xpixels, plt.figSize.ypixels = 0, 0
+}
+
+// size of the saved figure in inches
+func (plt *plotParameters) FigSizeInches(xwidth, ywidth float64) {
+	defer plt.lock()()
+	plt.dirty = true
+
+	if xwidth <= 0 || ywidth <= 0 {
+		plt.setErr(fmt.Errorf("plotter: invalid figure size %gx%g in", xwidth, ywidth))
+		return
+	}
+	plt.figSize.xwidth = vg.Length(xwidth) * vg.Inch
+	plt.figSize.ywidth = vg.Length(ywidth) * vg.Inch
+	plt.figSize.xpixels, plt.figSize.ypixels = 0, 0
+}
+
+// exact size of the saved figure in pixels at the figure dpi
+func (plt *plotParameters) FigSizePixels(xwidth, ywidth int) {
+	defer plt.lock()()
+	plt.dirty = true
+
+	if xwidth <= 0 || ywidth <= 0 {
+		plt.setErr(fmt.Errorf("plotter: invalid figure size %dx%d px", xwidth, ywidth))
+		return
+	}
+	plt.figSize.xpixels = xwidth
+	plt.figSize.ypixels = ywidth
+}
+
+// resolution of raster figures in dots per inch
+func (plt *plotParameters) FigDPI(dpi int) {
+	defer plt.lock()()
+	plt.dirty = true
+
+	if dpi <= 0 {
+		plt.setErr(fmt.Errorf("plotter: invalid figure dpi %d", dpi))
+		return
+	}
+	plt.figSize.dpi = dpi
 }
 
 // title for all plots
 func (plt *plotParameters) Title(title string) {
+	if plt.parent != nil {
+		plt.parent.Title(title)
+		return
+	}
+	defer plt.lock()()
+	plt.dirty = true
+
 	plt.plot.Title.Text = title
 }
 
 // xlabel for all plots
 func (plt *plotParameters) XLabel(xlabel string) {
+	if plt.sharesX() {
+		plt.parent.XLabel(xlabel)
+		return
+	}
+	defer plt.lock()()
+	plt.dirty = true
+
 	plt.plot.X.Label.Text = xlabel
 }
 
 // ylabel for all plots
 func (plt *plotParameters) YLabel(ylabel string) {
+	if plt.sharesY() {
+		plt.parent.YLabel(ylabel)
+		return
+	}
+	defer plt.lock()()
+	plt.dirty = true
+
 	plt.plot.Y.Label.Text = ylabel
 }
 
-// legend mainly used in lines plots
+// legend naming the plotted series in order, without names the legend
+// shows the series given a label, in the order they are drawn
 func (plt *plotParameters) Legend(str ...string) {
-	// legend style
+	defer plt.lock()()
+	plt.dirty = true
+
+	// the series shown in the legend, without the images
+	var series []*series
+	for _, s := range plt.series {
+		if s.thumbs != nil {
+			series = append(series, s)
+		}
+	}
+	if len(str) > len(series) {
+		plt.setErr(ErrLegendSize)
+		return
+	}
+
+	// the legends of a twin go into the legend box of its parent
+	legends := &plt.legendBox
+	if plt.parent != nil {
+		plt.parent.dirty = true
+		legends = &plt.parent.legendBox
+	}
+
+	if len(str) == 0 {
+		legends.labelled = true
+		return
+	}
 	for i, legend := range str {
-		plt.plot.Legend.Add(legend, plt.legends[i]...)
-		plt.plot.Legend.XOffs = -5. * vg.Millimeter
-		plt.plot.Legend.Padding = vg.Millimeter
+		legends.entries = append(legends.entries, legendEntry{name: legend, series: series[i]})
 	}
 }
 
 // set the x-axis vies limits
 func (plt *plotParameters) XLim(xmin, xmax float64) {
+	if plt.sharesX() {
+		plt.parent.XLim(xmin, xmax)
+		return
+	}
+	defer plt.lock()()
+	plt.dirty = true
+
 	if xmin < xmax {
 		plt.plot.X.Min = xmin
 		plt.plot.X.Max = xmax
+		plt.xLimits = true
 	}
 }
 
 // set the x-axis vies limits
 func (plt *plotParameters) YLim(ymin, ymax float64) {
+	if plt.sharesY() {
+		plt.parent.YLim(ymin, ymax)
+		return
+	}
+	defer plt.lock()()
+	plt.dirty = true
+
 	if ymin < ymax {
 		plt.plot.Y.Min = ymin
 		plt.plot.Y.Max = ymax
+		plt.yLimits = true
+	}
+}
+
+// remove all the plotted data, keeping the title, the axis labels and the figure settings
+func (plt *plotParameters) Clear() {
+	defer plt.lock()()
+	plt.clear()
+}
+
+func (plt *plotParameters) clear() {
+	plt.dirty = true
+	p := plot.New()
+	p.Title.Text = plt.plot.Title.Text
+	p.X.Label.Text = plt.plot.X.Label.Text
+	p.Y.Label.Text = plt.plot.Y.Label.Text
+
+	plt.plot = p
+	plt.lineOptions = lineOptions{usedColors: make(map[color.Color]bool)}
+	plt.bars = nil
+	plt.hists = nil
+	plt.series = nil
+	plt.stale = false
+	plt.legendBox.entries = nil
+	plt.legendBox.labelled = false
+	plt.colorBar = colorBar{}
+	plt.xLimits, plt.yLimits = false, false
+	for _, twin := range plt.withTwins()[1:] {
+		twin.clear()
 	}
 }
 
-// draw grid with both vertical and horizontal lines
-func (plt *plotParameters) Grid() {
-	plt.plot.Add(plotter.NewGrid())
+// start a new empty plot, keeping only the figure settings
+func (plt *plotParameters) Reset() {
+	defer plt.lock()()
+	plt.clear()
+	plt.plot = plot.New()
+	plt.xScale, plt.yScale = Linear, Linear
+	plt.xTicks, plt.yTicks = nil, nil
+	plt.grid = nil
+	plt.legendBox = legendBox{}
+	plt.twinX, plt.twinY = nil, nil
+	plt.err = nil
 }
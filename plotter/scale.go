@@ -0,0 +1,381 @@
+package plotter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+)
+
+// scale of an axis, the zero value is a linear scale
+type scaleType struct {
+	kind      string
+	base      float64 // base of a log scale
+	linthresh float64 // range around zero where a symlog scale is linear
+}
+
+var (
+	Linear = scaleType{kind: "linear"}
+	Log    = scaleType{kind: "log", base: 10}
+	Logit  = scaleType{kind: "logit"} // for values between 0 and 1, such as probabilities
+)
+
+// log scale with the given base, ticks are placed at the powers of the base
+func LogBase(base float64) scaleType {
+	return scaleType{kind: "log", base: base}
+}
+
+// symmetric log scale, linear between -linthresh and linthresh
+// and logarithmic outside, for data crossing zero
+func SymLog(linthresh float64) scaleType {
+	return scaleType{kind: "symlog", linthresh: linthresh}
+}
+
+// scale of the x-axis
+func (plt *plotParameters) XScale(scale scaleType) {
+	if plt.sharesX() {
+		plt.parent.XScale(scale)
+		return
+	}
+	defer plt.lock()()
+	plt.dirty = true
+
+	if err := scale.check(); err != nil {
+		plt.setErr(err)
+		return
+	}
+	plt.xScale = scale
+}
+
+// scale of the y-axis
+func (plt *plotParameters) YScale(scale scaleType) {
+	if plt.sharesY() {
+		plt.parent.YScale(scale)
+		return
+	}
+	defer plt.lock()()
+	plt.dirty = true
+
+	if err := scale.check(); err != nil {
+		plt.setErr(err)
+		return
+	}
+	plt.yScale = scale
+}
+
+// line plot with a log scale on the x-axis
+func (plt *plotParameters) SemiLogX(x, y []float64, options ...func(*lineOptions)) Line {
+	line := plt.Plot(x, y, options...)
+	plt.XScale(Log)
+	return line
+}
+
+// line plot with a log scale on the y-axis
+func (plt *plotParameters) SemiLogY(x, y []float64, options ...func(*lineOptions)) Line {
+	line := plt.Plot(x, y, options...)
+	plt.YScale(Log)
+	return line
+}
+
+// line plot with a log scale on both axes
+func (plt *plotParameters) LogLog(x, y []float64, options ...func(*lineOptions)) Line {
+	line := plt.Plot(x, y, options...)
+	plt.XScale(Log)
+	plt.YScale(Log)
+	return line
+}
+
+func (s scaleType) check() error {
+	switch s.kind {
+	case "", "linear", "logit":
+		return nil
+	case "log":
+		if s.base <= 0 || s.base == 1 || math.IsInf(s.base, 0) || math.IsNaN(s.base) {
+			return fmt.Errorf("plotter: invalid log scale base %g", s.base)
+		}
+		return nil
+	case "symlog":
+		if !(s.linthresh > 0) || math.IsInf(s.linthresh, 0) {
+			return fmt.Errorf("plotter: invalid symlog linear threshold %g", s.linthresh)
+		}
+		return nil
+	}
+	return fmt.Errorf("plotter: unknown axis scale %q", s.kind)
+}
+
+// position of x along an axis with the scale, linear in the drawn position
+func (s scaleType) forward(x float64) float64 {
+	switch s.kind {
+	case "log":
+		return math.Log(x)
+	case "symlog":
+		return symlogScale{linthresh: s.linthresh}.transform(x)
+	case "logit":
+		return logit(x)
+	}
+	return x
+}
+
+// value at the position u along an axis with the scale, the inverse of forward
+func (s scaleType) inverse(u float64) float64 {
+	switch s.kind {
+	case "log":
+		return math.Exp(u)
+	case "symlog":
+		return math.Copysign(s.linthresh*math.Expm1(math.Abs(u)), u)
+	case "logit":
+		return 1 / (1 + math.Exp(-u))
+	}
+	return u
+}
+
+// set the scales and the ticks of the axes for drawing, with the axis ranges
+// moved inside the domain of the scales, returns a function restoring the axes
+func (plt *plotParameters) prepare() (restore func()) {
+	for _, p := range plt.withTwins() {
+		if p.stale {
+			p.refit()
+		}
+	}
+
+	restores := []func(){plt.saveAxes()}
+	xPlotters, yPlotters := plt.shown(), plt.shown()
+
+	// a shared axis covers the data of both plots
+	if twin := plt.twinX; twin != nil {
+		restores = append(restores, twin.saveAxes())
+		if !plt.xLimits {
+			shareRange(&plt.plot.X, twin.plot.X)
+		}
+		xPlotters = append(xPlotters, twin.shown()...)
+		applyScale(&twin.plot.Y, twin.yScale, yCoord, twin.shown())
+		applyTicks(&twin.plot.Y, twin.yTicks, true)
+	}
+	if twin := plt.twinY; twin != nil {
+		restores = append(restores, twin.saveAxes())
+		if !plt.yLimits {
+			shareRange(&plt.plot.Y, twin.plot.Y)
+		}
+		yPlotters = append(yPlotters, twin.shown()...)
+		applyScale(&twin.plot.X, twin.xScale, xCoord, twin.shown())
+		applyTicks(&twin.plot.X, twin.xTicks, false)
+	}
+	applyScale(&plt.plot.X, plt.xScale, xCoord, xPlotters)
+	applyScale(&plt.plot.Y, plt.yScale, yCoord, yPlotters)
+	applyTicks(&plt.plot.X, plt.xTicks, false)
+	applyTicks(&plt.plot.Y, plt.yTicks, true)
+
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+}
+
+// returns a function restoring the ranges, the scales and the ticks of the axes
+func (plt *plotParameters) saveAxes() (restore func()) {
+	x, y := plt.plot.X, plt.plot.Y
+	return func() {
+		plt.plot.X.Min, plt.plot.X.Max = x.Min, x.Max
+		plt.plot.X.Scale, plt.plot.X.Tick.Marker, plt.plot.X.Tick.Label = x.Scale, x.Tick.Marker, x.Tick.Label
+		plt.plot.Y.Min, plt.plot.Y.Max = y.Min, y.Max
+		plt.plot.Y.Scale, plt.plot.Y.Tick.Marker, plt.plot.Y.Tick.Label = y.Scale, y.Tick.Marker, y.Tick.Label
+	}
+}
+
+func xCoord(xy plotter.XY) float64 { return xy.X }
+func yCoord(xy plotter.XY) float64 { return xy.Y }
+
+func applyScale(axis *plot.Axis, scale scaleType, coord func(plotter.XY) float64, plotters []plot.Plotter) {
+	switch scale.kind {
+	case "log":
+		axis.Min, axis.Max = domainRange(axis, coord, plotters, 0, math.Inf(1))
+		if axis.Min == axis.Max {
+			axis.Min, axis.Max = axis.Min/scale.base, axis.Max*scale.base
+		}
+		axis.Scale = logScale{}
+		axis.Tick.Marker = logTicks{base: scale.base}
+	case "symlog":
+		axis.Scale = symlogScale{linthresh: scale.linthresh}
+		axis.Tick.Marker = symlogTicks{linthresh: scale.linthresh}
+	case "logit":
+		axis.Min, axis.Max = domainRange(axis, coord, plotters, 0, 1)
+		if axis.Min == axis.Max {
+			axis.Min, axis.Max = axis.Min/10, 1-(1-axis.Max)/10
+		}
+		axis.Scale = logitScale{}
+		axis.Tick.Marker = logitTicks{}
+	}
+}
+
+// axis range moved inside the open interval (lo, hi), using the smallest
+// and largest data inside the interval when the axis range is outside
+func domainRange(axis *plot.Axis, coord func(plotter.XY) float64, plotters []plot.Plotter, lo, hi float64) (min, max float64) {
+	min, max = axis.Min, axis.Max
+	if min > lo && max < hi && min <= max {
+		return min, max
+	}
+
+	dataMin, dataMax := math.Inf(1), math.Inf(-1)
+	inside := func(v float64) {
+		if v > lo && v < hi {
+			dataMin, dataMax = math.Min(dataMin, v), math.Max(dataMax, v)
+		}
+	}
+	for _, p := range plotters {
+		switch p := p.(type) {
+		case *histogram:
+			for i, v := range p.heights {
+				inside(coord(plotter.XY{X: p.edges[i], Y: p.bottoms[i] + v}))
+				inside(coord(plotter.XY{X: p.edges[i+1], Y: p.bottoms[i]}))
+			}
+		case *plotter.BarChart:
+			for i := range p.Values {
+				if p.Horizontal {
+					inside(coord(plotter.XY{X: p.BarHeight(i)}))
+				} else {
+					inside(coord(plotter.XY{Y: p.BarHeight(i)}))
+				}
+			}
+		case plotter.XYer:
+			for i := 0; i < p.Len(); i++ {
+				x, y := p.XY(i)
+				inside(coord(plotter.XY{X: x, Y: y}))
+			}
+		case plot.DataRanger:
+			xmin, xmax, ymin, ymax := p.DataRange()
+			inside(coord(plotter.XY{X: xmin, Y: ymin}))
+			inside(coord(plotter.XY{X: xmax, Y: ymax}))
+		}
+	}
+	inside(min)
+	inside(max)
+
+	// no data inside the domain, a default range
+	if dataMin > dataMax {
+		if math.IsInf(hi, 1) {
+			return 1, 10
+		}
+		return 0.01, 0.99
+	}
+	if !(min > lo) || min > max {
+		min = dataMin
+	}
+	if !(max < hi) || min > max {
+		max = dataMax
+	}
+	return min, max
+}
+
+// log scale where values outside its domain are placed far below the axis,
+// so that they are clipped instead of making the plot fail
+type logScale struct{}
+
+func (logScale) Normalize(min, max, x float64) float64 {
+	if x <= 0 {
+		x = min * 1e-6
+	}
+	return plot.LogScale{}.Normalize(min, max, x)
+}
+
+type symlogScale struct {
+	linthresh float64
+}
+
+func (s symlogScale) Normalize(min, max, x float64) float64 {
+	fmin := s.transform(min)
+	return (s.transform(x) - fmin) / (s.transform(max) - fmin)
+}
+
+func (s symlogScale) transform(x float64) float64 {
+	return math.Copysign(math.Log1p(math.Abs(x)/s.linthresh), x)
+}
+
+type logitScale struct{}
+
+func (logitScale) Normalize(min, max, x float64) float64 {
+	fmin := logit(min)
+	return (logit(x) - fmin) / (logit(max) - fmin)
+}
+
+func logit(x float64) float64 {
+	x = math.Max(1e-12, math.Min(1-1e-12, x))
+	return math.Log(x / (1 - x))
+}
+
+// ticks at the powers of the base, with minor ticks at their multiples
+type logTicks struct {
+	base float64
+}
+
+func (t logTicks) Ticks(min, max float64) []plot.Tick {
+	if t.base == 10 {
+		return plot.LogTicks{Prec: -1}.Ticks(min, max)
+	}
+
+	lo := math.Floor(math.Log(min) / math.Log(t.base))
+	hi := math.Ceil(math.Log(max) / math.Log(t.base))
+	var ticks []plot.Tick
+	for k := lo; k <= hi; k++ {
+		v := math.Pow(t.base, k)
+		label := strconv.FormatFloat(v, 'g', 4, 64)
+		if t.base == math.E {
+			label = "e^" + strconv.FormatFloat(k, 'g', -1, 64)
+		}
+		ticks = append(ticks, plot.Tick{Value: v, Label: label})
+
+		if t.base == math.Trunc(t.base) && k < hi {
+			for m := 2.0; m < t.base; m++ {
+				ticks = append(ticks, plot.Tick{Value: m * v})
+			}
+		}
+	}
+	return ticks
+}
+
+// ticks at zero and at the powers of ten outside the linear range
+type symlogTicks struct {
+	linthresh float64
+}
+
+func (t symlogTicks) Ticks(min, max float64) []plot.Tick {
+	ticks := []plot.Tick{{Value: 0, Label: "0"}}
+	extent := math.Max(math.Abs(min), math.Abs(max))
+	for k := math.Ceil(math.Log10(t.linthresh)); math.Pow(10, k) <= extent; k++ {
+		v := math.Pow(10, k)
+		label := strconv.FormatFloat(v, 'g', -1, 64)
+		ticks = append(ticks,
+			plot.Tick{Value: v, Label: label},
+			plot.Tick{Value: -v, Label: "-" + label})
+	}
+
+	inRange := 0
+	for _, tick := range ticks {
+		if tick.Value >= min && tick.Value <= max {
+			inRange++
+		}
+	}
+	if inRange < 2 {
+		return plot.DefaultTicks{}.Ticks(min, max)
+	}
+	return ticks
+}
+
+// ticks at 0.5 and at the decades towards 0 and 1
+type logitTicks struct{}
+
+func (logitTicks) Ticks(min, max float64) []plot.Tick {
+	ticks := []plot.Tick{{Value: 0.5, Label: "0.5"}}
+	for k := 1; k <= 12; k++ {
+		v := math.Pow(10, -float64(k))
+		if v < min && 1-v > max {
+			break
+		}
+		ticks = append(ticks,
+			plot.Tick{Value: v, Label: strconv.FormatFloat(v, 'g', -1, 64)},
+			plot.Tick{Value: 1 - v, Label: strconv.FormatFloat(1-v, 'f', k, 64)})
+	}
+	return ticks
+}
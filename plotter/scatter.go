@@ -4,6 +4,7 @@ import (
 	"image/color"
 
 	"github.com/mazznoer/colorgrad"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/plot/font"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
@@ -15,6 +16,16 @@ type scatterOptions struct {
 	marker     draw.GlyphDrawer
 	markerSize font.Length
 	colorBar   colorBar
+	zLimits    bool // z range fixed by WithScatterColorLimits
+	zMin, zMax float64
+	label      string
+}
+
+// name of the scatter points in the legend
+func WithScatterLabel(label string) func(*scatterOptions) {
+	return func(so *scatterOptions) {
+		so.label = label
+	}
 }
 
 func WithMarkerColor(color colorType) func(*scatterOptions) {
@@ -30,6 +41,15 @@ func WithScatterGradient(gradient colorgrad.Gradient) func(*scatterOptions) {
 	}
 }
 
+// z values mapped to the ends of the gradient and of the colorbar, instead of
+// the range of z, which SetData then keeps for new data
+func WithScatterColorLimits(min, max float64) func(*scatterOptions) {
+	return func(so *scatterOptions) {
+		so.zLimits = true
+		so.zMin, so.zMax = min, max
+	}
+}
+
 func WithScatterMarker(marker markerType) func(*scatterOptions) {
 	return func(so *scatterOptions) {
 		so.marker = marker
@@ -52,3 +72,12 @@ func WithScatterColorbar(position positionType) func(*scatterOptions) {
 		}
 	}
 }
+
+// z values mapped to the ends of the gradient, the limits of the options
+// or the range of z
+func (so scatterOptions) zRange(z []float64) (min, max float64) {
+	if so.zLimits {
+		return so.zMin, so.zMax
+	}
+	return floats.Min(z), floats.Max(z)
+}
@@ -1,18 +1,14 @@
 package plotter
 
 import (
+	"fmt"
+	"image"
 	"image/color"
-	"log"
+	"io"
 	"os"
+	"sync"
 
-	"gioui.org/app"
-	"gioui.org/io/system"
-	"gioui.org/layout"
-	"gioui.org/op"
-	"gioui.org/op/paint"
-	"gioui.org/unit"
 	"gonum.org/v1/plot"
-	"gonum.org/v1/plot/font"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
 	"gonum.org/v1/plot/vg/vgimg"
@@ -21,121 +17,427 @@ import (
 type Subplot interface {
 	Subplot(row, col int) PlotterInterface
 	FigSize(xwidth, ywidth int)
-	Save(name string)
-	Show()
+	FigSizeInches(xwidth, ywidth float64)
+	FigSizePixels(xwidth, ywidth int)
+	FigDPI(dpi int)
+	Save(name string, options ...func(*saveOptions)) error
+	WriteTo(w io.Writer, format formatType) (int64, error)
+	Image() image.Image
+	Show(options ...func(*viewerOptions)) error
+	ShowAsync(options ...func(*viewerOptions)) *Window
+	ShowLater()
+	Viewer(options ...func(*viewerOptions)) *Viewer
+	ShowTerminal(options ...func(*terminalOptions)) error
+	SaveHTML(file string) error
+	Legend(options ...func(*legendOptions))
+	Clear()
+	Reset()
+	Err() error
 }
 
 func NewSubplot(rows, cols int) Subplot {
-	subplots := make([][]*plot.Plot, rows)
+	var err error
+	if rows < 1 || cols < 1 {
+		err = fmt.Errorf("plotter: invalid subplot grid %dx%d", rows, cols)
+		rows, cols = 0, 0
+	}
+
+	subplots := make([][]*plotParameters, rows)
 	for j := range subplots {
-		subplots[j] = make([]*plot.Plot, cols)
+		subplots[j] = make([]*plotParameters, cols)
 	}
 
-	return &subplotParameters{
+	plt := &subplotParameters{
 		rows:     rows,
 		cols:     cols,
 		subplots: subplots,
 		figSize: figSize{
-			xwidth: 15,
-			ywidth: 10,
-		},
-		figure: vgimg.PngCanvas{
-			Canvas: &vgimg.Canvas{},
+			dpi:    vgimg.DefaultDPI,
+			xwidth: 15 * vg.Centimeter,
+			ywidth: 10 * vg.Centimeter,
 		},
+		err: err,
+		mu:  new(sync.Mutex),
 	}
+	return plt
 }
 
 // initialize each subplot individually
 func (plt *subplotParameters) Subplot(row, col int) PlotterInterface {
-	p := plot.New()
-	plt.subplots[row][col] = p
-	return &plotParameters{
-		plot: p,
+	defer plt.lock()()
+	p := &plotParameters{
+		plot: plot.New(),
 		lineOptions: lineOptions{
 			usedColors: make(map[color.Color]bool),
 		},
+		mu: plt.mu,
+	}
+
+	// a subplot outside the grid is kept apart and never drawn
+	if row < 0 || row >= plt.rows || col < 0 || col >= plt.cols {
+		plt.setErr(fmt.Errorf("plotter: subplot (%d, %d) outside the %dx%d grid", row, col, plt.rows, plt.cols))
+		return p
+	}
+	plt.subplots[row][col] = p
+	plt.dirty = true
+	return p
+}
+
+// record the first error found while building the subplot
+func (plt *subplotParameters) setErr(err error) {
+	if plt.err == nil {
+		plt.err = err
+	}
+}
+
+// first error found while building the subplot or any of its plots
+func (plt *subplotParameters) Err() error {
+	defer plt.lock()()
+	return plt.firstErr()
+}
+
+func (plt *subplotParameters) firstErr() error {
+	if plt.err != nil {
+		return plt.err
+	}
+	for _, row := range plt.subplots {
+		for _, p := range row {
+			if p != nil {
+				if err := p.firstErr(); err != nil {
+					return err
+				}
+			}
+		}
 	}
+	return nil
 }
 
 // draw plot to a figure
-func (plt *subplotParameters) DrawPlot() {
-	xwidth := font.Length(plt.figSize.xwidth) * vg.Centimeter
-	ywidth := font.Length(plt.figSize.ywidth) * vg.Centimeter
+func (plt *subplotParameters) DrawPlot(format formatType) error {
+	xwidth, ywidth := plt.figSize.size()
+
+	// new canvas for the output format
+	img, err := newCanvas(plt.figSize.dpi, xwidth, ywidth, format)
+	if err != nil {
+		return err
+	}
 
-	// new image canvas
-	img := vgimg.New(xwidth, ywidth)
+	plots := make([][]*plot.Plot, plt.rows)
+	for j := range plots {
+		plots[j] = make([]*plot.Plot, plt.cols)
+		for i, p := range plt.subplots[j] {
+			if p != nil {
+				plots[j][i] = p.plot
+				defer p.prepare()()
+			}
+		}
+	}
 
-	canvases := plot.Align(plt.subplots, draw.Tiles{
+	// space for a figure legend outside the subplots
+	canvas := draw.New(img)
+	var legend *legendLayout
+	var place func(draw.Canvas) vg.Point
+	if plt.legend != nil {
+		legend = newLegendLayout(legendBox{entries: plt.legendEntries(), options: *plt.legend})
+	}
+	if legend != nil {
+		place = legend.reserve(&canvas)
+	}
+
+	canvases := plot.Align(plots, draw.Tiles{
 		Rows: plt.rows,
 		Cols: plt.cols,
 		PadX: vg.Centimeter,
 		PadY: vg.Centimeter,
-	}, draw.New(img))
+	}, canvas)
+	var points []vg.Point
 	for j := 0; j < plt.rows; j++ {
 		for i := 0; i < plt.cols; i++ {
-			if plt.subplots[j][i] != nil {
-				plt.subplots[j][i].Draw(canvases[j][i])
+			p := plt.subplots[j][i]
+			if p == nil {
+				continue
+			}
+
+			// the figure legend replaces the legends of the subplots
+			box := p.legendBox
+			if legend != nil {
+				p.legendBox = legendBox{}
+				points = append(points, p.dataPoints(p.plot.DataCanvas(canvases[j][i]))...)
 			}
+			p.draw(canvases[j][i])
+			p.legendBox = box
 		}
 	}
+	if legend != nil {
+		legend.drawIn(img, canvas, place, func() []vg.Point { return points })
+	}
+
+	plt.figure = img
+	plt.format = format
+	plt.dirty = false
+	return nil
+}
+
+// show plot in an interactive graphical window and wait until it is closed,
+// from a goroutine other than the one running Main on macOS
+func (plt *subplotParameters) Show(options ...func(*viewerOptions)) error {
+	return plt.ShowAsync(options...).Wait()
+}
+
+// show plot in an interactive graphical window without waiting for it to be closed
+func (plt *subplotParameters) ShowAsync(options ...func(*viewerOptions)) *Window {
+	return showAsync(plt, options...)
+}
+
+// queue the plot to be shown with the other queued figures by ShowAll
+func (plt *subplotParameters) ShowLater() {
+	addPending(plt)
+}
+
+// draw the plot inline in a terminal with graphics support, such as over SSH
+func (plt *subplotParameters) ShowTerminal(options ...func(*terminalOptions)) error {
+	return showTerminal(plt, options...)
+}
+
+// interactive view of the plot, as shown by Show
+func (plt *subplotParameters) Viewer(options ...func(*viewerOptions)) *Viewer {
+	return newViewer(plt, options...)
+}
+
+// figure drawn at an exact size in pixels until restore is called
+func (plt *subplotParameters) pixelSize(width, height int) (restore func()) {
+	size := plt.figSize
+	plt.figSize.xpixels, plt.figSize.ypixels = width, height
+	plt.format = ""
+	return func() {
+		plt.figSize = size
+		plt.format = ""
+	}
+}
 
-	plt.figure = vgimg.PngCanvas{Canvas: img}
-}
-
-// show plot in graphical window
-func (plt *subplotParameters) Show() {
-	if plt.figure.Image() == nil {
-		plt.DrawPlot()
-	}
-
-	// graphical window creation
-	imgData := plt.figure.Image()
-	window := app.NewWindow(
-		app.Title("Plot Viewer"),
-		app.Size(unit.Dp(float32(imgData.Bounds().Dx())),
-			unit.Dp(float32(imgData.Bounds().Dy()))),
-	)
-
-	var ops op.Ops
-	for e := range window.Events() {
-		switch e := e.(type) {
-		case system.DestroyEvent:
-			return
-		case system.FrameEvent:
-			gtx := layout.NewContext(&ops, e)
-			layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-				imgOp := paint.NewImageOp(imgData)
-				imgOp.Add(gtx.Ops)
-				paint.PaintOp{}.Add(gtx.Ops)
-				return layout.Dimensions{Size: gtx.Constraints.Max}
-			})
-			e.Frame(gtx.Ops)
+// plots zoomed and panned by the viewer
+func (plt *subplotParameters) views() []*plotParameters {
+	var views []*plotParameters
+	for _, row := range plt.subplots {
+		for _, p := range row {
+			if p != nil {
+				views = append(views, p)
+			}
 		}
 	}
-	app.Main()
+	return views
+}
+
+// lock of the figure, held while it is drawn or changed by the handles and the viewer
+func (plt *subplotParameters) locker() sync.Locker {
+	return plt.mu
+}
+
+// hold the lock of the figure until unlock is called
+func (plt *subplotParameters) lock() (unlock func()) {
+	plt.mu.Lock()
+	return plt.mu.Unlock
+}
+
+func (plt *subplotParameters) resolution() int {
+	return plt.figSize.dpi
+}
+
+func (plt *subplotParameters) rasterSize() (xpixels, ypixels int) {
+	return plt.figSize.pixels()
+}
+
+// save the plot to a file in the format given by its extension
+func (plt *subplotParameters) Save(file string, options ...func(*saveOptions)) error {
+	plt.mu.Lock()
+	defer plt.mu.Unlock()
+	return plt.save(file, options...)
 }
 
-// save the plot to an image file
-func (plt *subplotParameters) Save(file string) {
-	// save the plot to a PNG file.
-	if plt.figure.Image() == nil {
-		plt.DrawPlot()
+func (plt *subplotParameters) save(file string, options ...func(*saveOptions)) error {
+	if err := plt.firstErr(); err != nil {
+		return err
+	}
+
+	format, err := saveFormat(file, options...)
+	if err != nil {
+		return err
 	}
 
 	// save the image to a file
 	w, err := os.Create(file)
 	if err != nil {
-		log.Panic(err)
+		return err
+	}
+
+	if _, err := plt.writeTo(w, format); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// write the plot to w in the given format
+func (plt *subplotParameters) WriteTo(w io.Writer, format formatType) (int64, error) {
+	plt.mu.Lock()
+	defer plt.mu.Unlock()
+	return plt.writeTo(w, format)
+}
+
+func (plt *subplotParameters) writeTo(w io.Writer, format formatType) (int64, error) {
+	if err := plt.firstErr(); err != nil {
+		return 0, err
+	}
+
+	if plt.isDirty() || plt.format != format {
+		if err := plt.DrawPlot(format); err != nil {
+			return 0, err
+		}
+	}
+	return plt.figure.WriteTo(w)
+}
+
+// rendered plot as a raster image, nil if the plot has errors
+func (plt *subplotParameters) Image() image.Image {
+	plt.mu.Lock()
+	defer plt.mu.Unlock()
+	return plt.image()
+}
+
+func (plt *subplotParameters) image() image.Image {
+	if plt.firstErr() != nil {
+		return nil
 	}
-	defer w.Close()
 
-	if _, err := plt.figure.WriteTo(w); err != nil {
-		panic(err)
+	if plt.isDirty() || plt.format != PNG {
+		if err := plt.DrawPlot(PNG); err != nil {
+			return nil
+		}
 	}
+	return plt.figure.(vgimg.PngCanvas).Image()
 }
 
 // size of the saved figure
 func (plt *subplotParameters) FigSize(xwidth, ywidth int) {
-	plt.figSize.xwidth = xwidth
-	plt.figSize.ywidth = ywidth
+	defer plt.lock()()
+	plt.dirty = true
+	if xwidth <= 0 || ywidth <= 0 {
+		plt.setErr(fmt.Errorf("plotter: invalid figure size %dx%d cm", xwidth, ywidth))
+		return
+	}
+	plt.figSize.xwidth = vg.Length(xwidth) * vg.Centimeter
+	plt.figSize.ywidth = vg.Length(ywidth) * vg.Centimeter
+	plt.figSize.xpixels, plt.figSize.ypixels = 0, 0
+}
+
+// size of the saved figure in inches
+func (plt *subplotParameters) FigSizeInches(xwidth, ywidth float64) {
+	defer plt.lock()()
+	plt.dirty = true
+	if xwidth <= 0 || ywidth <= 0 {
+		plt.setErr(fmt.Errorf("plotter: invalid figure size %gx%g in", xwidth, ywidth))
+		return
+	}
+	plt.figSize.xwidth = vg.Length(xwidth) * vg.Inch
+	plt.figSize.ywidth = vg.Length(ywidth) * vg.Inch
+	plt.figSize.xpixels, plt.figSize.ypixels = 0, 0
+}
+
+// exact size of the saved figure in pixels at the figure dpi
+func (plt *subplotParameters) FigSizePixels(xwidth, ywidth int) {
+	defer plt.lock()()
+	plt.dirty = true
+	if xwidth <= 0 || ywidth <= 0 {
+		plt.setErr(fmt.Errorf("plotter: invalid figure size %dx%d px", xwidth, ywidth))
+		return
+	}
+	plt.figSize.xpixels = xwidth
+	plt.figSize.ypixels = ywidth
+}
+
+// resolution of raster figures in dots per inch
+func (plt *subplotParameters) FigDPI(dpi int) {
+	defer plt.lock()()
+	plt.dirty = true
+	if dpi <= 0 {
+		plt.setErr(fmt.Errorf("plotter: invalid figure dpi %d", dpi))
+		return
+	}
+	plt.figSize.dpi = dpi
+}
+
+// one legend for the whole figure, with the legend entries of all the subplots
+func (plt *subplotParameters) Legend(options ...func(*legendOptions)) {
+	defer plt.lock()()
+	plt.dirty = true
+
+	// on the right of the subplots unless placed elsewhere
+	options = append([]func(*legendOptions){WithLegendLocation(OutsideRight)}, options...)
+	lo, err := newLegendOptions(options...)
+	if err != nil {
+		plt.setErr(err)
+		return
+	}
+	plt.legend = &lo
+}
+
+// legend entries and labelled series of all the subplots, once for each name
+func (plt *subplotParameters) legendEntries() []legendEntry {
+	var entries []legendEntry
+	seen := make(map[string]bool)
+	for _, row := range plt.subplots {
+		for _, p := range row {
+			if p == nil {
+				continue
+			}
+			for _, e := range append(p.legendEntries(), p.labelledEntries()...) {
+				if !seen[e.name] {
+					seen[e.name] = true
+					entries = append(entries, e)
+				}
+			}
+		}
+	}
+	return entries
+}
+
+// figure must be drawn again after a change to the subplot or any of its plots
+func (plt *subplotParameters) isDirty() bool {
+	if plt.dirty {
+		return true
+	}
+	for _, row := range plt.subplots {
+		for _, p := range row {
+			if p != nil && p.isDirty() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// remove all the plotted data from every subplot, keeping their titles and axis labels
+func (plt *subplotParameters) Clear() {
+	defer plt.lock()()
+	plt.dirty = true
+	for _, row := range plt.subplots {
+		for _, p := range row {
+			if p != nil {
+				p.clear()
+			}
+		}
+	}
+}
+
+// remove every subplot, keeping only the figure settings
+func (plt *subplotParameters) Reset() {
+	defer plt.lock()()
+	plt.dirty = true
+	for _, row := range plt.subplots {
+		for i := range row {
+			row[i] = nil
+		}
+	}
+	plt.legend = nil
+	plt.err = nil
 }
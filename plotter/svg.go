@@ -0,0 +1,252 @@
+package plotter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	xfont "golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/vg"
+)
+
+// SVG figure of SaveHTML, drawn as by vgsvg with groups around the drawing of
+// the series and of their legend entries, so that the page finds them
+var markedSVG formatType = "svg+marks"
+
+// digits of the coordinates, as written by vgsvg
+const svgPrecision = 5
+
+// canvas writing an SVG document, with groups started and ended while drawing
+type svgCanvas struct {
+	w, h  vg.Length
+	buf   bytes.Buffer
+	stack []svgContext
+}
+
+type svgContext struct {
+	color      color.Color
+	dashes     []vg.Length
+	dashOffset vg.Length
+	lineWidth  vg.Length
+	groups     int // groups of the transforms, ended by Pop
+}
+
+func newSVGCanvas(w, h vg.Length) *svgCanvas {
+	c := &svgCanvas{w: w, h: h, stack: []svgContext{{}}}
+
+	// origin at the bottom left, the group is ended by WriteTo
+	fmt.Fprintf(&c.buf, "<g transform=\"scale(1, -1) translate(0, -%s)\">\n", svgNumber(h.Points()))
+	vg.Initialize(c)
+	return c
+}
+
+func (c *svgCanvas) context() *svgContext {
+	return &c.stack[len(c.stack)-1]
+}
+
+// start a group of the class for the series, ended by the returned function
+func (c *svgCanvas) group(class string, series int) (end func()) {
+	fmt.Fprintf(&c.buf, "<g class=%q data-series=\"%d\">\n", class, series)
+	return func() {
+		c.buf.WriteString("</g>\n")
+	}
+}
+
+func (c *svgCanvas) Size() (w, h vg.Length) {
+	return c.w, c.h
+}
+
+func (c *svgCanvas) SetLineWidth(w vg.Length) {
+	c.context().lineWidth = w
+}
+
+func (c *svgCanvas) SetLineDash(dashes []vg.Length, offset vg.Length) {
+	c.context().dashes = dashes
+	c.context().dashOffset = offset
+}
+
+func (c *svgCanvas) SetColor(clr color.Color) {
+	c.context().color = clr
+}
+
+func (c *svgCanvas) transform(transform string) {
+	fmt.Fprintf(&c.buf, "<g transform=\"%s\">\n", transform)
+	c.context().groups++
+}
+
+func (c *svgCanvas) Rotate(rad float64) {
+	c.transform("rotate(" + svgNumber(rad*180/math.Pi) + ")")
+}
+
+func (c *svgCanvas) Translate(pt vg.Point) {
+	c.transform("translate(" + svgNumber(pt.X.Points()) + ", " + svgNumber(pt.Y.Points()) + ")")
+}
+
+func (c *svgCanvas) Scale(x, y float64) {
+	c.transform("scale(" + svgNumber(x) + ", " + svgNumber(y) + ")")
+}
+
+func (c *svgCanvas) Push() {
+	top := *c.context()
+	top.groups = 0
+	c.stack = append(c.stack, top)
+}
+
+func (c *svgCanvas) Pop() {
+	for i := 0; i < c.context().groups; i++ {
+		c.buf.WriteString("</g>\n")
+	}
+	c.stack = c.stack[:len(c.stack)-1]
+}
+
+func (c *svgCanvas) Stroke(path vg.Path) {
+	ctx := c.context()
+	if ctx.lineWidth.Points() <= 0 {
+		return
+	}
+	dashes := "none"
+	if len(ctx.dashes) > 0 {
+		parts := make([]string, len(ctx.dashes))
+		for i, d := range ctx.dashes {
+			parts[i] = svgNumber(d.Points())
+		}
+		dashes = strings.Join(parts, ",")
+	}
+	fmt.Fprintf(&c.buf, "<path d=%q style=\"fill:none;stroke:%s;stroke-opacity:%s;stroke-width:%s;stroke-dasharray:%s;stroke-dashoffset:%s\"/>\n",
+		svgPath(path), svgColor(ctx.color), svgOpacity(ctx.color), svgNumber(ctx.lineWidth.Points()),
+		dashes, svgNumber(ctx.dashOffset.Points()))
+}
+
+func (c *svgCanvas) Fill(path vg.Path) {
+	clr := c.context().color
+	fmt.Fprintf(&c.buf, "<path d=%q style=\"fill:%s;fill-opacity:%s\"/>\n", svgPath(path), svgColor(clr), svgOpacity(clr))
+}
+
+func (c *svgCanvas) FillString(fnt font.Face, pt vg.Point, str string) {
+	var buf sfnt.Buffer
+	family, err := fnt.Face.Name(&buf, sfnt.NameIDFamily)
+	if err != nil {
+		family = string(fnt.Font.Typeface)
+	}
+	style := "normal"
+	switch fnt.Font.Style {
+	case xfont.StyleItalic:
+		style = "italic"
+	case xfont.StyleOblique:
+		style = "oblique"
+	}
+	clr := c.context().color
+	fmt.Fprintf(&c.buf, "<text x=\"%s\" y=\"%s\" transform=\"scale(1, -1)\" style=\"font-family:%s;font-weight:%d;font-style:%s;font-size:%spx;fill:%s;fill-opacity:%s\">%s</text>\n",
+		svgNumber(pt.X.Points()), svgNumber(-pt.Y.Points()), html.EscapeString(family), 400+100*int(fnt.Font.Weight),
+		style, svgNumber(fnt.Font.Size.Points()), svgColor(clr), svgOpacity(clr), html.EscapeString(str))
+}
+
+func (c *svgCanvas) DrawImage(rect vg.Rectangle, img image.Image) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		panic(fmt.Errorf("plotter: encoding an image of the SVG figure: %v", err))
+	}
+	size := rect.Size()
+
+	// turned upside down so that the image is not drawn flipped
+	fmt.Fprintf(&c.buf, "<image x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" transform=\"scale(1, -1)\" xlink:href=\"data:image/png;base64,%s\"/>\n",
+		svgNumber(rect.Min.X.Points()), svgNumber(-rect.Min.Y.Points()-size.Y.Points()),
+		svgNumber(size.X.Points()), svgNumber(size.Y.Points()), base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+// write the document, the canvas may still be drawn and written again
+func (c *svgCanvas) WriteTo(w io.Writer) (int64, error) {
+	var doc bytes.Buffer
+	fmt.Fprintf(&doc, "<svg width=\"%spt\" height=\"%spt\" viewBox=\"0 0 %s %s\"\n\txmlns=\"http://www.w3.org/2000/svg\"\n\txmlns:xlink=\"http://www.w3.org/1999/xlink\">\n",
+		svgNumber(c.w.Points()), svgNumber(c.h.Points()), svgNumber(c.w.Points()), svgNumber(c.h.Points()))
+	doc.Write(c.buf.Bytes())
+	ends := 1
+	for _, ctx := range c.stack {
+		ends += ctx.groups
+	}
+	doc.WriteString(strings.Repeat("</g>\n", ends))
+	doc.WriteString("</svg>\n")
+	return doc.WriteTo(w)
+}
+
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', svgPrecision, 64)
+}
+
+// hexadecimal color without its alpha, black for no color
+func svgColor(clr color.Color) string {
+	if clr == nil {
+		clr = color.Black
+	}
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+func svgOpacity(clr color.Color) string {
+	if clr == nil {
+		clr = color.Black
+	}
+	_, _, _, a := clr.RGBA()
+	return svgNumber(float64(a) / math.MaxUint16)
+}
+
+// path data of the path, with the arcs of vg as SVG arcs
+func svgPath(path vg.Path) string {
+	var buf strings.Builder
+	var x, y float64
+	for _, comp := range path {
+		switch comp.Type {
+		case vg.MoveComp:
+			x, y = comp.Pos.X.Points(), comp.Pos.Y.Points()
+			buf.WriteString("M" + svgNumber(x) + "," + svgNumber(y))
+		case vg.LineComp:
+			x, y = comp.Pos.X.Points(), comp.Pos.Y.Points()
+			buf.WriteString("L" + svgNumber(x) + "," + svgNumber(y))
+		case vg.ArcComp:
+			r := comp.Radius.Points()
+			cx, cy := comp.Pos.X.Points(), comp.Pos.Y.Points()
+			sin, cos := math.Sincos(comp.Start)
+			if x0, y0 := cx+r*cos, cy+r*sin; x0 != x || y0 != y {
+				buf.WriteString("L" + svgNumber(x0) + "," + svgNumber(y0))
+			}
+
+			// an SVG arc cannot end where it starts, a full circle is drawn as two halves
+			arcs, angle := 1, comp.Angle
+			if math.Abs(angle) >= 2*math.Pi {
+				arcs, angle = 2, math.Copysign(2*math.Pi, angle)/2
+			}
+			large, sweep := 0, 0
+			if math.Abs(angle) >= math.Pi {
+				large = 1
+			}
+			if angle >= 0 {
+				sweep = 1
+			}
+			for i := 1; i <= arcs; i++ {
+				sin, cos := math.Sincos(comp.Start + float64(i)*angle)
+				x, y = cx+r*cos, cy+r*sin
+				fmt.Fprintf(&buf, "A%s,%s 0 %d %d %s,%s", svgNumber(r), svgNumber(r), large, sweep, svgNumber(x), svgNumber(y))
+			}
+		case vg.CurveComp:
+			buf.WriteString(map[int]string{1: "Q", 2: "C"}[len(comp.Control)])
+			for _, pt := range comp.Control {
+				buf.WriteString(svgNumber(pt.X.Points()) + "," + svgNumber(pt.Y.Points()) + ",")
+			}
+			x, y = comp.Pos.X.Points(), comp.Pos.Y.Points()
+			buf.WriteString(svgNumber(x) + "," + svgNumber(y))
+		case vg.CloseComp:
+			buf.WriteString("Z")
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,249 @@
+package plotter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+)
+
+type terminalType string
+
+// graphics protocols of the terminal backend
+var (
+	Kitty  terminalType = "kitty"  // kitty graphics protocol
+	ITerm2 terminalType = "iterm2" // iTerm2 inline images, also shown by WezTerm
+	Sixel  terminalType = "sixel"  // DEC sixel graphics
+)
+
+// size of the base64 chunks sent with the kitty protocol
+const kittyChunk = 4096
+
+type terminalOptions struct {
+	terminal         terminalType
+	output           io.Writer
+	xpixels, ypixels int
+}
+
+// protocol used to draw the figure instead of the one detected from the environment
+func WithTerminal(terminal terminalType) func(*terminalOptions) {
+	return func(to *terminalOptions) {
+		to.terminal = terminal
+	}
+}
+
+// writer of the escape sequences instead of the standard output
+func WithTerminalOutput(w io.Writer) func(*terminalOptions) {
+	return func(to *terminalOptions) {
+		to.output = w
+	}
+}
+
+// size in pixels of the figure drawn in the terminal instead of the figure size
+func WithTerminalSize(xwidth, ywidth int) func(*terminalOptions) {
+	return func(to *terminalOptions) {
+		to.xpixels, to.ypixels = xwidth, ywidth
+	}
+}
+
+// graphics protocol of the terminal, from its environment variables
+func detectTerminal() (terminalType, error) {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || program == "ghostty":
+		return Kitty, nil
+	case program == "iTerm.app" || os.Getenv("LC_TERMINAL") == "iTerm2" || program == "WezTerm":
+		return ITerm2, nil
+	case strings.Contains(term, "sixel") || term == "mlterm" || strings.HasPrefix(term, "foot") ||
+		strings.HasPrefix(term, "yaft") || program == "mintty":
+		return Sixel, nil
+	}
+	return "", fmt.Errorf("plotter: no terminal graphics protocol found for TERM=%q, choose one with WithTerminal", term)
+}
+
+// draw the figure inline in the terminal
+func showTerminal(figure viewable, options ...func(*terminalOptions)) error {
+	// default options
+	to := terminalOptions{
+		output: os.Stdout,
+	}
+
+	// apply additional options
+	for _, option := range options {
+		option(&to)
+	}
+
+	if to.terminal == "" {
+		terminal, err := detectTerminal()
+		if err != nil {
+			return err
+		}
+		to.terminal = terminal
+	}
+	if (to.xpixels != 0 || to.ypixels != 0) && (to.xpixels <= 0 || to.ypixels <= 0) {
+		return fmt.Errorf("plotter: invalid terminal size %dx%d px", to.xpixels, to.ypixels)
+	}
+
+	img, err := terminalImage(figure, to.xpixels, to.ypixels)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(to.output)
+	switch to.terminal {
+	case Kitty:
+		err = writeKitty(w, img)
+	case ITerm2:
+		err = writeITerm2(w, img)
+	case Sixel:
+		err = writeSixel(w, img)
+	default:
+		return fmt.Errorf("plotter: unsupported terminal %q", to.terminal)
+	}
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// figure drawn at the size in pixels, or at its own size for a zero size
+func terminalImage(figure viewable, xpixels, ypixels int) (image.Image, error) {
+	mu := figure.locker()
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := figure.firstErr(); err != nil {
+		return nil, err
+	}
+	if xpixels > 0 {
+		defer figure.pixelSize(xpixels, ypixels)()
+	}
+	return figure.image(), nil
+}
+
+// image encoded as PNG and base64, with the size of the PNG in bytes
+func base64PNG(img image.Image) (data string, size int, err error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", 0, err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), buf.Len(), nil
+}
+
+// image sent as PNG in chunks with the kitty graphics protocol
+func writeKitty(w *bufio.Writer, img image.Image) error {
+	data, _, err := base64PNG(img)
+	if err != nil {
+		return err
+	}
+
+	for first := true; first || data != ""; first = false {
+		chunk := data
+		if len(chunk) > kittyChunk {
+			chunk = chunk[:kittyChunk]
+		}
+		data = data[len(chunk):]
+
+		more := 0
+		if data != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(w, "\x1b_Ga=T,f=100,m=%d;%s\x1b\\", more, chunk)
+		} else {
+			fmt.Fprintf(w, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	_, err = w.WriteString("\n")
+	return err
+}
+
+// image sent as PNG with the inline image protocol of iTerm2
+func writeITerm2(w *bufio.Writer, img image.Image) error {
+	data, n, err := base64PNG(img)
+	if err != nil {
+		return err
+	}
+
+	size := img.Bounds().Size()
+	_, err = fmt.Fprintf(w, "\x1b]1337;File=inline=1;size=%d;width=%dpx;height=%dpx;preserveAspectRatio=1:%s\a\n",
+		n, size.X, size.Y, data)
+	return err
+}
+
+// image sent as sixels, with a palette of at most 256 colors
+func writeSixel(w *bufio.Writer, img image.Image) error {
+	frame := quantize(img)
+	size := frame.Rect.Size()
+
+	// raster attributes and palette, with the channels in percent
+	fmt.Fprintf(w, "\x1bP0;1;0q\"1;1;%d;%d", size.X, size.Y)
+	for i, c := range frame.Palette {
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(w, "#%d;2;%d;%d;%d", i, percent(r), percent(g), percent(b))
+	}
+
+	// bands of six rows, drawn once for each color found in the band
+	sixels := make([]byte, size.X)
+	for top := 0; top < size.Y; top += 6 {
+		used := make([]bool, len(frame.Palette))
+		for y := top; y < top+6 && y < size.Y; y++ {
+			for _, c := range frame.Pix[y*frame.Stride : y*frame.Stride+size.X] {
+				used[c] = true
+			}
+		}
+
+		first := true
+		for c := range used {
+			if !used[c] {
+				continue
+			}
+			for x := range sixels {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < size.Y; dy++ {
+					if int(frame.Pix[(top+dy)*frame.Stride+x]) == c {
+						bits |= 1 << dy
+					}
+				}
+				sixels[x] = '?' + bits
+			}
+
+			if !first {
+				w.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(w, "#%d", c)
+			writeSixelRuns(w, sixels)
+		}
+		w.WriteByte('-')
+	}
+	_, err := w.WriteString("\x1b\\\n")
+	return err
+}
+
+// sixels with the runs of more than three equal sixels repeated by a count
+func writeSixelRuns(w *bufio.Writer, sixels []byte) {
+	for i := 0; i < len(sixels); {
+		j := i
+		for j < len(sixels) && sixels[j] == sixels[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(w, "!%d%c", n, sixels[i])
+		} else {
+			w.Write(sixels[i:j])
+		}
+		i = j
+	}
+}
+
+// channel of a color in percent, rounded
+func percent(c uint32) uint32 {
+	return (c*100 + 0xffff/2) / 0xffff
+}
@@ -0,0 +1,217 @@
+package plotter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// plot drawn in the terminal at the size in pixels with the protocol, and the written escape sequences
+func terminalOutput(t *testing.T, terminal terminalType, width, height int) (*plotParameters, string) {
+	t.Helper()
+	p := NewPlot().(*plotParameters)
+	p.Plot([]float64{1, 2, 3}, []float64{1, 4, 9})
+
+	var buf bytes.Buffer
+	err := p.ShowTerminal(WithTerminal(terminal), WithTerminalOutput(&buf), WithTerminalSize(width, height))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, buf.String()
+}
+
+func decodePNG(t *testing.T, data string) image.Image {
+	t.Helper()
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestShowTerminalKitty(t *testing.T) {
+	_, out := terminalOutput(t, Kitty, 200, 150)
+	if !strings.HasSuffix(out, "\n") {
+		t.Fatalf("output %q does not end with a new line", out)
+	}
+
+	var data strings.Builder
+	chunks := strings.Split(strings.TrimSuffix(out, "\n"), "\x1b\\")
+	chunks = chunks[:len(chunks)-1]
+	if len(chunks) < 2 {
+		t.Fatalf("%d chunk, want the image split in several", len(chunks))
+	}
+	for i, chunk := range chunks {
+		prefix := "\x1b_Gm="
+		if i == 0 {
+			prefix = "\x1b_Ga=T,f=100,m="
+		}
+		more := "1;"
+		if i == len(chunks)-1 {
+			more = "0;"
+		}
+		if !strings.HasPrefix(chunk, prefix+more) {
+			t.Fatalf("chunk %d starts with %q, want %q", i, chunk[:len(prefix)+2], prefix+more)
+		}
+		payload := chunk[len(prefix+more):]
+		if len(payload) > kittyChunk {
+			t.Fatalf("chunk %d of %d bytes", i, len(payload))
+		}
+		data.WriteString(payload)
+	}
+
+	if size := decodePNG(t, data.String()).Bounds().Size(); size != image.Pt(200, 150) {
+		t.Fatalf("image of %v px, want 200x150", size)
+	}
+}
+
+func TestShowTerminalITerm2(t *testing.T) {
+	_, out := terminalOutput(t, ITerm2, 40, 30)
+	if !strings.HasSuffix(out, "\a\n") {
+		t.Fatalf("output does not end with BEL and a new line")
+	}
+
+	var size int
+	var header string
+	i := strings.Index(out, ":")
+	if i < 0 {
+		t.Fatalf("output %q without data", out)
+	}
+	header = out[:i]
+	if _, err := fmt.Sscanf(header, "\x1b]1337;File=inline=1;size=%d;", &size); err != nil {
+		t.Fatalf("header %q: %v", header, err)
+	}
+	if want := fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=40px;height=30px;preserveAspectRatio=1", size); header != want {
+		t.Fatalf("header %q, want %q", header, want)
+	}
+
+	data := strings.TrimSuffix(out[i+1:], "\a\n")
+	if n := base64.StdEncoding.DecodedLen(len(data)); n < size || n > size+2 {
+		t.Fatalf("%d bytes of data, size %d", n, size)
+	}
+	if size := decodePNG(t, data).Bounds().Size(); size != image.Pt(40, 30) {
+		t.Fatalf("image of %v px, want 40x30", size)
+	}
+}
+
+// decode the sixel image into the palette index of each pixel
+func decodeSixel(t *testing.T, out string, width, height int) []uint8 {
+	t.Helper()
+	header := fmt.Sprintf("\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	if !strings.HasPrefix(out, header) || !strings.HasSuffix(out, "\x1b\\\n") {
+		t.Fatalf("output %.20q... does not start with %q and end with ST", out, header)
+	}
+	data := strings.TrimSuffix(out[len(header):], "\x1b\\\n")
+
+	pix := make([]uint8, width*height)
+	number := func(i int) (int, int) {
+		n := 0
+		for ; i < len(data) && data[i] >= '0' && data[i] <= '9'; i++ {
+			n = 10*n + int(data[i]-'0')
+		}
+		return n, i
+	}
+	c, x, top := 0, 0, 0
+	for i := 0; i < len(data); {
+		switch ch := data[i]; {
+		case ch == '#':
+			c, i = number(i + 1)
+			// palette definitions are skipped
+			for i < len(data) && data[i] == ';' {
+				_, i = number(i + 1)
+			}
+		case ch == '$':
+			x, i = 0, i+1
+		case ch == '-':
+			x, top, i = 0, top+6, i+1
+		case ch == '!' || ch >= '?' && ch <= '~':
+			count := 1
+			if ch == '!' {
+				count, i = number(i + 1)
+			}
+			bits := data[i] - '?'
+			for n := 0; n < count; n++ {
+				for dy := 0; dy < 6; dy++ {
+					if bits&(1<<dy) != 0 && top+dy < height && x < width {
+						pix[(top+dy)*width+x] = uint8(c)
+					}
+				}
+				x++
+			}
+			i++
+		default:
+			t.Fatalf("unexpected sixel byte %q at %d", ch, i)
+		}
+	}
+	if top != 5*6 {
+		t.Fatalf("%d bands of six rows, want 5", top/6)
+	}
+	return pix
+}
+
+func TestShowTerminalSixel(t *testing.T) {
+	p, out := terminalOutput(t, Sixel, 40, 30)
+
+	img, err := terminalImage(p, 40, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := quantize(img)
+	got := decodeSixel(t, out, 40, 30)
+	if !bytes.Equal(got, want.Pix) {
+		t.Fatal("decoded sixels differ from the quantized figure")
+	}
+}
+
+func TestShowTerminalErrors(t *testing.T) {
+	p := NewPlot()
+	p.Plot([]float64{1, 2}, []float64{1, 2})
+	var buf bytes.Buffer
+	if err := p.ShowTerminal(WithTerminal(Kitty), WithTerminalOutput(&buf), WithTerminalSize(40, 0)); err == nil {
+		t.Error("no error for a terminal size of 40x0 px")
+	}
+	if err := p.ShowTerminal(WithTerminal("vt100"), WithTerminalOutput(&buf)); err == nil {
+		t.Error("no error for an unknown terminal")
+	}
+
+	p.Plot(nil, nil)
+	if err := p.ShowTerminal(WithTerminal(Kitty), WithTerminalOutput(&buf)); err != ErrEmptyData {
+		t.Errorf("error %v, want %v", err, ErrEmptyData)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("%d bytes written by failed calls", buf.Len())
+	}
+}
+
+func TestDetectTerminal(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want terminalType
+	}{
+		{map[string]string{"KITTY_WINDOW_ID": "1"}, Kitty},
+		{map[string]string{"TERM": "xterm-kitty"}, Kitty},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, ITerm2},
+		{map[string]string{"LC_TERMINAL": "iTerm2"}, ITerm2},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, ITerm2},
+		{map[string]string{"TERM": "foot"}, Sixel},
+		{map[string]string{"TERM": "xterm-sixel"}, Sixel},
+		{map[string]string{"TERM": "xterm-256color"}, ""},
+	}
+	for _, test := range tests {
+		for _, name := range []string{"KITTY_WINDOW_ID", "TERM", "TERM_PROGRAM", "LC_TERMINAL"} {
+			t.Setenv(name, test.env[name])
+		}
+		got, err := detectTerminal()
+		if got != test.want || (err == nil) != (test.want != "") {
+			t.Errorf("env %v: terminal %q, error %v, want %q", test.env, got, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,496 @@
+package plotter
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// shades of the cells covered by heatmaps, images, bars and other areas, from light to dark
+var (
+	blockShades = []rune(" ░▒▓█")
+	asciiShades = []rune(" .:+#")
+)
+
+// size of the text drawn by String
+const (
+	textCols = 80
+	textRows = 24
+)
+
+type textOptions struct {
+	color bool
+	ascii bool
+}
+
+// color the series and the areas with ANSI 256-color escape sequences
+func WithANSIColor() func(*textOptions) {
+	return func(to *textOptions) {
+		to.color = true
+	}
+}
+
+// plain ASCII characters, one dot for each character, instead of braille dots
+func WithASCII() func(*textOptions) {
+	return func(to *textOptions) {
+		to.ascii = true
+	}
+}
+
+// grid of characters of the data area, with the dots of the lines and scatter points
+// and the shades of the other plotters
+type textCanvas struct {
+	cols, rows int
+	dotsX      int // dots for each character along x and along y
+	dotsY      int
+	dots       []uint8       // braille bits, or the character in ASCII
+	fg         []color.Color // color of the last dot drawn in each character
+	bg         []color.Color // color of the area under each character
+	shade      []int         // shade of the area under each character, 0 for none
+}
+
+// plot drawn as text, with braille dots for the data, in a grid of cols x rows characters
+// including the title, the axes and their ticks, and the legend
+func (plt *plotParameters) RenderText(w io.Writer, cols, rows int, options ...func(*textOptions)) error {
+	plt.mu.Lock()
+	defer plt.mu.Unlock()
+
+	// default options
+	to := textOptions{}
+
+	// apply additional options
+	for _, option := range options {
+		option(&to)
+	}
+
+	if err := plt.firstErr(); err != nil {
+		return err
+	}
+	defer plt.prepare()()
+	for _, p := range plt.withTwins() {
+		sanitizeRange(&p.plot.X)
+		sanitizeRange(&p.plot.Y)
+	}
+
+	// tick labels of the y-axis on the left of the data area
+	yTicks := majorTicks(plt.plot.Y)
+	labelWidth := 0
+	for _, t := range yTicks {
+		if n := utf8.RuneCountInString(t.Label); n > labelWidth {
+			labelWidth = n
+		}
+	}
+
+	// lines of text around the data area
+	title, xLabel, yLabel := plt.plot.Title.Text, plt.plot.X.Label.Text, plt.plot.Y.Label.Text
+	entries := plt.legendEntries()
+	extra := 2 + len(entries)
+	for _, text := range []string{title, xLabel, yLabel} {
+		if text != "" {
+			extra++
+		}
+	}
+	width, height := cols-labelWidth-1, rows-extra
+	if width < 2 || height < 2 {
+		return fmt.Errorf("plotter: text of %dx%d characters too small for the plot", cols, rows)
+	}
+
+	tc := newTextCanvas(width, height, to.ascii)
+	for _, p := range plt.withTwins() {
+		tc.drawPlot(p)
+	}
+
+	var b strings.Builder
+	if title != "" {
+		b.WriteString(center(title, cols))
+		b.WriteByte('\n')
+	}
+	if yLabel != "" {
+		b.WriteString(yLabel)
+		b.WriteByte('\n')
+	}
+
+	// rows of the data area, with the y-axis and its tick labels
+	tickRows := make(map[int]string)
+	for _, t := range yTicks {
+		tickRows[tc.row(plt.plot.Y.Norm(t.Value))] = t.Label
+	}
+	axis, tick := "│", "┤"
+	if to.ascii {
+		axis, tick = "|", "+"
+	}
+	for row := 0; row < height; row++ {
+		label, ok := tickRows[row]
+		b.WriteString(strings.Repeat(" ", labelWidth-utf8.RuneCountInString(label)))
+		b.WriteString(label)
+		if ok {
+			b.WriteString(tick)
+		} else {
+			b.WriteString(axis)
+		}
+		tc.writeRow(&b, row, to)
+		b.WriteByte('\n')
+	}
+
+	// x-axis with its ticks and their labels, skipping the labels that overlap
+	xTicks := majorTicks(plt.plot.X)
+	line := []rune(strings.Repeat("─", width))
+	corner, xTick := "└", '┬'
+	if to.ascii {
+		line = []rune(strings.Repeat("-", width))
+		corner, xTick = "+", '+'
+	}
+	labels := []rune(strings.Repeat(" ", width+labelWidth+1))
+	end := 0
+	for _, t := range xTicks {
+		col := tc.col(plt.plot.X.Norm(t.Value))
+		line[col] = xTick
+
+		text := []rune(t.Label)
+		start := labelWidth + 1 + col - len(text)/2
+		if start < end || start+len(text) > len(labels) {
+			continue
+		}
+		copy(labels[start:], text)
+		end = start + len(text) + 1
+	}
+	b.WriteString(strings.Repeat(" ", labelWidth) + corner + string(line) + "\n")
+	b.WriteString(strings.TrimRight(string(labels), " ") + "\n")
+	if xLabel != "" {
+		b.WriteString(center(xLabel, cols))
+		b.WriteByte('\n')
+	}
+
+	// legend entries below the plot, with the dots in the color of their series
+	for _, e := range entries {
+		mark := "⣿⣿"
+		if to.ascii {
+			mark = "**"
+		}
+		if c := seriesColor(e.series); to.color && c != nil {
+			mark = ansiForeground(c) + mark + "\x1b[0m"
+		}
+		b.WriteString(strings.Repeat(" ", labelWidth+1) + mark + " " + e.name + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// plot drawn as braille text of 80x24 characters, or its error
+func (plt *plotParameters) String() string {
+	var b strings.Builder
+	if err := plt.RenderText(&b, textCols, textRows); err != nil {
+		return err.Error()
+	}
+	return b.String()
+}
+
+// labelled ticks inside the range of the axis
+func majorTicks(axis plot.Axis) []plot.Tick {
+	var ticks []plot.Tick
+	for _, t := range axis.Tick.Marker.Ticks(axis.Min, axis.Max) {
+		if !t.IsMinor() && t.Value >= axis.Min && t.Value <= axis.Max {
+			ticks = append(ticks, t)
+		}
+	}
+	return ticks
+}
+
+// text centered in a line of the given width
+func center(text string, width int) string {
+	pad := (width - utf8.RuneCountInString(text)) / 2
+	if pad < 0 {
+		pad = 0
+	}
+	return strings.Repeat(" ", pad) + text
+}
+
+func newTextCanvas(cols, rows int, ascii bool) *textCanvas {
+	tc := &textCanvas{
+		cols:  cols,
+		rows:  rows,
+		dotsX: 2,
+		dotsY: 4,
+		dots:  make([]uint8, cols*rows),
+		fg:    make([]color.Color, cols*rows),
+		bg:    make([]color.Color, cols*rows),
+		shade: make([]int, cols*rows),
+	}
+	if ascii {
+		tc.dotsX, tc.dotsY = 1, 1
+	}
+	return tc
+}
+
+// column of the characters at the fraction x of the width
+func (tc *textCanvas) col(x float64) int {
+	return int(math.Round(x * float64(tc.cols-1)))
+}
+
+// row of the characters at the fraction y of the height, from the bottom
+func (tc *textCanvas) row(y float64) int {
+	return int(math.Round((1 - y) * float64(tc.rows-1)))
+}
+
+// draw the series of a plot, with the dots of its lines and scatter points over its areas
+func (tc *textCanvas) drawPlot(p *plotParameters) {
+	var areas []plot.Plotter
+	for _, pl := range p.shown() {
+		switch pl.(type) {
+		case *plotter.Line, *plotter.Scatter:
+		default:
+			areas = append(areas, pl)
+		}
+	}
+	tc.drawAreas(p.plot, areas)
+
+	for _, pl := range p.shown() {
+		switch pl := pl.(type) {
+		case *plotter.Line:
+			tc.drawLine(p.plot, pl.XYs, pl.Color)
+		case *plotter.Scatter:
+			for j, xy := range pl.XYs {
+				c := pl.Color
+				if pl.GlyphStyleFunc != nil {
+					c = pl.GlyphStyleFunc(j).Color
+				}
+				x, y := tc.dot(p.plot, xy.X, xy.Y)
+				tc.set(int(math.Round(x)), int(math.Round(y)), c, 'o')
+			}
+		}
+	}
+}
+
+// position in dots of a point, from the top left corner of the data area
+func (tc *textCanvas) dot(p *plot.Plot, x, y float64) (float64, float64) {
+	w, h := float64(tc.cols*tc.dotsX-1), float64(tc.rows*tc.dotsY-1)
+	return p.X.Norm(x) * w, (1 - p.Y.Norm(y)) * h
+}
+
+// set the dot at a position, or the character in ASCII, ignoring dots outside the data area
+func (tc *textCanvas) set(x, y int, c color.Color, char byte) {
+	if x < 0 || y < 0 || x >= tc.cols*tc.dotsX || y >= tc.rows*tc.dotsY {
+		return
+	}
+	i := y/tc.dotsY*tc.cols + x/tc.dotsX
+	if tc.dotsX == 1 {
+		tc.dots[i] = char
+	} else {
+		// bits of the braille dots, numbered down the left column and then the right one
+		bits := [2][4]uint8{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+		tc.dots[i] |= bits[x%2][y%4]
+	}
+	tc.fg[i] = c
+}
+
+// draw the segments between the points of a line, clipped to the data area
+func (tc *textCanvas) drawLine(p *plot.Plot, xys plotter.XYs, c color.Color) {
+	w, h := float64(tc.cols*tc.dotsX-1), float64(tc.rows*tc.dotsY-1)
+	for i := 1; i < len(xys); i++ {
+		x0, y0 := tc.dot(p, xys[i-1].X, xys[i-1].Y)
+		x1, y1 := tc.dot(p, xys[i].X, xys[i].Y)
+		x0, y0, x1, y1, ok := clip(x0, y0, x1, y1, w, h)
+		if !ok {
+			continue
+		}
+
+		// steps of at most one dot along the longest side of the segment
+		steps := int(math.Ceil(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))))
+		for s := 0; s <= steps; s++ {
+			t := 0.0
+			if steps > 0 {
+				t = float64(s) / float64(steps)
+			}
+			tc.set(int(math.Round(x0+t*(x1-x0))), int(math.Round(y0+t*(y1-y0))), c, '*')
+		}
+	}
+}
+
+// segment clipped to the rectangle [0, w] x [0, h], false if it is outside or not finite
+func clip(x0, y0, x1, y1, w, h float64) (float64, float64, float64, float64, bool) {
+	for _, v := range []float64{x0, y0, x1, y1} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0, 0, 0, 0, false
+		}
+	}
+
+	// Liang-Barsky clipping of the parameter t of the segment
+	t0, t1 := 0.0, 1.0
+	dx, dy := x1-x0, y1-y0
+	for _, e := range [][2]float64{{-dx, x0}, {dx, w - x0}, {-dy, y0}, {dy, h - y0}} {
+		p, q := e[0], e[1]
+		if p == 0 {
+			if q < 0 {
+				return 0, 0, 0, 0, false
+			}
+			continue
+		}
+		r := q / p
+		if p < 0 {
+			t0 = math.Max(t0, r)
+		} else {
+			t1 = math.Min(t1, r)
+		}
+	}
+	if t0 > t1 {
+		return 0, 0, 0, 0, false
+	}
+	return x0 + t0*dx, y0 + t0*dy, x0 + t1*dx, y0 + t1*dy, true
+}
+
+// draw the plotters other than lines and scatter points with gonum at one pixel
+// for each character, shading the characters with the pixels
+func (tc *textCanvas) drawAreas(p *plot.Plot, areas []plot.Plotter) {
+	if len(areas) == 0 {
+		return
+	}
+
+	c := vgimg.NewWith(
+		vgimg.UseWH(vg.Length(tc.cols), vg.Length(tc.rows)),
+		vgimg.UseDPI(72),
+		vgimg.UseBackgroundColor(color.Transparent),
+	)
+	dc := draw.Canvas{Canvas: c, Rectangle: vg.Rectangle{Max: vg.Point{X: vg.Length(tc.cols), Y: vg.Length(tc.rows)}}}
+	for _, pl := range areas {
+		pl.Plot(dc, p)
+	}
+
+	img := c.Image()
+	for row := 0; row < tc.rows; row++ {
+		for col := 0; col < tc.cols; col++ {
+			i := row*tc.cols + col
+			nc := color.NRGBAModel.Convert(img.At(img.Bounds().Min.X+col, img.Bounds().Min.Y+row)).(color.NRGBA)
+			if nc.A < 26 {
+				continue
+			}
+
+			// darker and more opaque colors give darker shades, any filled area at least the lightest
+			alpha := float64(nc.A) / 255
+			lum := (0.299*float64(nc.R) + 0.587*float64(nc.G) + 0.114*float64(nc.B)) / 255
+			level := alpha * (1 - lum)
+			if alpha > 0.5 {
+				level = math.Max(level, 0.25)
+			}
+			tc.shade[i] = int(math.Max(1, math.Round(level*float64(len(blockShades)-1))))
+			tc.bg[i] = over(nc, color.White)
+		}
+	}
+}
+
+// color drawn over an opaque background
+func over(c color.NRGBA, bg color.Color) color.Color {
+	a := float64(c.A) / 255
+	r, g, b, _ := bg.RGBA()
+	mix := func(v uint8, w uint32) uint8 {
+		return uint8(math.Round(a*float64(v) + (1-a)*float64(w>>8)))
+	}
+	return color.RGBA{mix(c.R, r), mix(c.G, g), mix(c.B, b), 255}
+}
+
+// write a row of characters of the data area
+func (tc *textCanvas) writeRow(b *strings.Builder, row int, to textOptions) {
+	shades := blockShades
+	if to.ascii {
+		shades = asciiShades
+	}
+
+	last := ""
+	for col := 0; col < tc.cols; col++ {
+		i := row*tc.cols + col
+		var char rune
+		switch {
+		case tc.dots[i] != 0 && to.ascii:
+			char = rune(tc.dots[i])
+		case tc.dots[i] != 0:
+			char = 0x2800 + rune(tc.dots[i])
+		case tc.shade[i] != 0 && to.color:
+			char = ' '
+		default:
+			char = shades[tc.shade[i]]
+		}
+
+		if to.color {
+			// the areas are the background of the dots, or shades without them
+			style := ""
+			if tc.shade[i] != 0 {
+				style += ansiBackground(tc.bg[i])
+			}
+			if tc.dots[i] != 0 && tc.fg[i] != nil {
+				style += ansiForeground(tc.fg[i])
+			}
+			if style != last {
+				if last != "" {
+					b.WriteString("\x1b[0m")
+				}
+				b.WriteString(style)
+				last = style
+			}
+		}
+		b.WriteRune(char)
+	}
+	if last != "" {
+		b.WriteString("\x1b[0m")
+	}
+}
+
+// color of the line, points or area of a series, nil if it has none
+func seriesColor(s *series) color.Color {
+	for _, pl := range s.plotters {
+		switch pl := pl.(type) {
+		case *plotter.Line:
+			return pl.Color
+		case *plotter.Scatter:
+			return pl.Color
+		case *plotter.Polygon:
+			return pl.Color
+		case *plotter.BarChart:
+			return pl.Color
+		}
+	}
+	return nil
+}
+
+// escape sequence of a foreground color, nothing for black so that
+// the dots keep the color of the terminal text
+func ansiForeground(c color.Color) string {
+	if c == nil {
+		return ""
+	}
+	r, g, b, _ := c.RGBA()
+	if r>>8 < 40 && g>>8 < 40 && b>>8 < 40 {
+		return ""
+	}
+	return fmt.Sprintf("\x1b[38;5;%dm", ansi256(c))
+}
+
+func ansiBackground(c color.Color) string {
+	return fmt.Sprintf("\x1b[48;5;%dm", ansi256(c))
+}
+
+// nearest color of the 6x6x6 cube or of the gray ramp of the 256-color palette
+func ansi256(c color.Color) int {
+	r, g, b, _ := c.RGBA()
+	r, g, b = r>>8, g>>8, b>>8
+	level := func(v uint32) int { return int(math.Round(float64(v) / 255 * 5)) }
+	if math.Max(float64(r), math.Max(float64(g), float64(b)))-math.Min(float64(r), math.Min(float64(g), float64(b))) < 10 {
+		gray := (int(r) + int(g) + int(b)) / 3
+		switch {
+		case gray < 8:
+			return 16
+		case gray > 248:
+			return 231
+		}
+		return 232 + int(math.Round(float64(gray-8)/247*23))
+	}
+	return 16 + 36*level(r) + 6*level(g) + level(b)
+}
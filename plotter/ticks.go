@@ -0,0 +1,242 @@
+package plotter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// format of the tick labels from the tick values
+type tickFormatType func(v float64) string
+
+// tick labels with a fixed number of decimals
+func FixedFormat(decimals int) tickFormatType {
+	return func(v float64) string {
+		return strconv.FormatFloat(v, 'f', decimals, 64)
+	}
+}
+
+// tick labels in scientific notation, such as 1.5e+03
+func ScientificFormat(decimals int) tickFormatType {
+	return func(v float64) string {
+		return strconv.FormatFloat(v, 'e', decimals, 64)
+	}
+}
+
+// tick labels as percentages of one, such as 25% for 0.25
+func PercentFormat(decimals int) tickFormatType {
+	return func(v float64) string {
+		return strconv.FormatFloat(100*v, 'f', decimals, 64) + "%"
+	}
+}
+
+// SI prefixes of the powers of a thousand
+var siPrefixes = map[int]string{
+	-24: "y", -21: "z", -18: "a", -15: "f", -12: "p", -9: "n", -6: "µ", -3: "m",
+	0: "", 3: "k", 6: "M", 9: "G", 12: "T", 15: "P", 18: "E", 21: "Z", 24: "Y",
+}
+
+// tick labels in engineering notation with SI prefixes, such as 1.5k for 1500
+func EngineeringFormat(decimals int) tickFormatType {
+	return func(v float64) string {
+		if v == 0 {
+			return strconv.FormatFloat(0, 'f', decimals, 64)
+		}
+		exp := int(math.Floor(math.Log10(math.Abs(v))/3)) * 3
+		exp = int(math.Max(-24, math.Min(24, float64(exp))))
+		return strconv.FormatFloat(v/math.Pow10(exp), 'f', decimals, 64) + siPrefixes[exp]
+	}
+}
+
+type tickOptions struct {
+	positions []float64
+	labels    []string
+	format    tickFormatType
+	major     int
+	minor     int
+	rotation  float64
+}
+
+// tick labels written with the given format, a custom func(float64) string can be given
+func WithTickFormat(format tickFormatType) func(*tickOptions) {
+	return func(to *tickOptions) {
+		to.format = format
+	}
+}
+
+// approximate number of major ticks, placed at round values
+func WithMajorTicks(n int) func(*tickOptions) {
+	return func(to *tickOptions) {
+		to.major = n
+	}
+}
+
+// number of minor ticks between two major ticks
+func WithMinorTicks(n int) func(*tickOptions) {
+	return func(to *tickOptions) {
+		to.minor = n
+	}
+}
+
+// rotation of the tick labels in degrees, counterclockwise
+func WithTickRotation(degrees float64) func(*tickOptions) {
+	return func(to *tickOptions) {
+		to.rotation = degrees
+	}
+}
+
+// ticks of the x-axis at the given positions with the given labels,
+// nil positions keep the automatic ticks and nil labels format the positions
+func (plt *plotParameters) XTicks(positions []float64, labels []string, options ...func(*tickOptions)) {
+	if plt.sharesX() {
+		plt.parent.XTicks(positions, labels, options...)
+		return
+	}
+	defer plt.lock()()
+	plt.dirty = true
+
+	to, err := newTickOptions(positions, labels, options...)
+	if err != nil {
+		plt.setErr(err)
+		return
+	}
+	plt.xTicks = to
+}
+
+// ticks of the y-axis at the given positions with the given labels,
+// nil positions keep the automatic ticks and nil labels format the positions
+func (plt *plotParameters) YTicks(positions []float64, labels []string, options ...func(*tickOptions)) {
+	if plt.sharesY() {
+		plt.parent.YTicks(positions, labels, options...)
+		return
+	}
+	defer plt.lock()()
+	plt.dirty = true
+
+	to, err := newTickOptions(positions, labels, options...)
+	if err != nil {
+		plt.setErr(err)
+		return
+	}
+	plt.yTicks = to
+}
+
+func newTickOptions(positions []float64, labels []string, options ...func(*tickOptions)) (*tickOptions, error) {
+	to := &tickOptions{
+		positions: positions,
+		labels:    labels,
+	}
+
+	// apply additional options
+	for _, option := range options {
+		option(to)
+	}
+
+	if labels != nil && len(labels) != len(positions) {
+		return nil, fmt.Errorf("plotter: len(labels) = %d and len(ticks) = %d mismatch", len(labels), len(positions))
+	}
+	if to.major < 0 || to.minor < 0 {
+		return nil, fmt.Errorf("plotter: invalid number of ticks %d major and %d minor", to.major, to.minor)
+	}
+	return to, nil
+}
+
+// set the ticker and the rotation of the tick labels of an axis,
+// on top of the ticks given by its scale
+func applyTicks(axis *plot.Axis, to *tickOptions, vertical bool) {
+	if to == nil {
+		return
+	}
+	axis.Tick.Marker = ticker{tickOptions: *to, base: axis.Tick.Marker}
+
+	if to.rotation != 0 {
+		axis.Tick.Label.Rotation = to.rotation * math.Pi / 180
+		if vertical {
+			axis.Tick.Label.XAlign, axis.Tick.Label.YAlign = draw.XRight, draw.YCenter
+		} else {
+			axis.Tick.Label.XAlign, axis.Tick.Label.YAlign = draw.XRight, draw.YTop
+			if to.rotation < 0 {
+				axis.Tick.Label.XAlign = draw.XLeft
+			}
+		}
+	}
+}
+
+// ticker placing the major ticks at the given positions, at round values
+// or where the base ticker puts them, and adding minor ticks between them
+type ticker struct {
+	tickOptions
+	base plot.Ticker
+}
+
+func (t ticker) Ticks(min, max float64) []plot.Tick {
+	var ticks []plot.Tick
+	switch {
+	case t.positions != nil:
+		for i, v := range t.positions {
+			tick := plot.Tick{Value: v, Label: strconv.FormatFloat(v, 'g', -1, 64)}
+			if t.labels != nil {
+				tick.Label = t.labels[i]
+			}
+			ticks = append(ticks, tick)
+		}
+	case t.major > 0:
+		ticks = roundTicks(min, max, t.major)
+	default:
+		ticks = t.base.Ticks(min, max)
+	}
+
+	// labels of the major ticks
+	if t.format != nil && t.labels == nil {
+		for i := range ticks {
+			if !ticks[i].IsMinor() {
+				ticks[i].Label = t.format(ticks[i].Value)
+			}
+		}
+	}
+
+	if t.minor == 0 {
+		return ticks
+	}
+
+	// evenly spaced minor ticks between the major ticks
+	var majors []plot.Tick
+	for _, tick := range ticks {
+		if !tick.IsMinor() {
+			majors = append(majors, tick)
+		}
+	}
+	ticks = majors
+	for i := 1; i < len(majors); i++ {
+		lo, hi := majors[i-1].Value, majors[i].Value
+		for j := 1; j <= t.minor; j++ {
+			ticks = append(ticks, plot.Tick{Value: lo + (hi-lo)*float64(j)/float64(t.minor+1)})
+		}
+	}
+	return ticks
+}
+
+// about n major ticks at multiples of 1, 2 or 5 times a power of ten
+func roundTicks(min, max float64, n int) []plot.Tick {
+	raw := (max - min) / float64(n)
+	if !(raw > 0) || math.IsInf(raw, 0) {
+		return []plot.Tick{{Value: min, Label: strconv.FormatFloat(min, 'g', 10, 64)}}
+	}
+	mag := math.Pow10(int(math.Floor(math.Log10(raw))))
+	step := mag
+	for _, m := range []float64{2, 5, 10} {
+		if raw/mag > m*0.75 {
+			step = m * mag
+		}
+	}
+
+	var ticks []plot.Tick
+	for k := math.Ceil(min / step); k*step <= max; k++ {
+		v := k * step
+		ticks = append(ticks, plot.Tick{Value: v, Label: strconv.FormatFloat(v, 'g', 10, 64)})
+	}
+	return ticks
+}
@@ -0,0 +1,220 @@
+package plotter
+
+import (
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// plot sharing the x-axis, drawn with its own y-axis on the right
+func (plt *plotParameters) TwinX() PlotterInterface {
+	if plt.parent != nil {
+		return plt.parent.TwinX()
+	}
+	defer plt.lock()()
+	if plt.twinX == nil {
+		plt.dirty = true
+		plt.twinX = plt.newTwin()
+	}
+	return plt.twinX
+}
+
+// plot sharing the y-axis, drawn with its own x-axis on the top
+func (plt *plotParameters) TwinY() PlotterInterface {
+	if plt.parent != nil {
+		return plt.parent.TwinY()
+	}
+	defer plt.lock()()
+	if plt.twinY == nil {
+		plt.dirty = true
+		plt.twinY = plt.newTwin()
+	}
+	return plt.twinY
+}
+
+func (plt *plotParameters) newTwin() *plotParameters {
+	return &plotParameters{
+		plot: plot.New(),
+		lineOptions: lineOptions{
+			usedColors: make(map[color.Color]bool),
+		},
+		parent: plt,
+		mu:     plt.mu,
+	}
+}
+
+// the plot is a twin sharing the x-axis of its parent
+func (plt *plotParameters) sharesX() bool {
+	return plt.parent != nil && plt.parent.twinX == plt
+}
+
+// the plot is a twin sharing the y-axis of its parent
+func (plt *plotParameters) sharesY() bool {
+	return plt.parent != nil && plt.parent.twinY == plt
+}
+
+// the plot and its twins
+func (plt *plotParameters) withTwins() []*plotParameters {
+	plots := []*plotParameters{plt}
+	for _, twin := range []*plotParameters{plt.twinX, plt.twinY} {
+		if twin != nil {
+			plots = append(plots, twin)
+		}
+	}
+	return plots
+}
+
+// figure must be drawn again after a change to the plot or its twins
+func (plt *plotParameters) isDirty() bool {
+	for _, p := range plt.withTwins() {
+		if p.dirty {
+			return true
+		}
+	}
+	return false
+}
+
+// range of the shared axis covering the data of both plots
+func shareRange(axis *plot.Axis, twin plot.Axis) {
+	axis.Min = math.Min(axis.Min, twin.Min)
+	axis.Max = math.Max(axis.Max, twin.Max)
+}
+
+// finite and non-empty axis range, as gonum does before drawing
+func sanitizeRange(axis *plot.Axis) {
+	if math.IsInf(axis.Min, 0) {
+		axis.Min = 0
+	}
+	if math.IsInf(axis.Max, 0) {
+		axis.Max = 0
+	}
+	if axis.Min > axis.Max {
+		axis.Min, axis.Max = axis.Max, axis.Min
+	}
+	if axis.Min == axis.Max {
+		axis.Min--
+		axis.Max++
+	}
+}
+
+// widest and tallest major tick labels
+func tickLabelSize(axis plot.Axis, ticks []plot.Tick) (width, height vg.Length) {
+	for _, t := range ticks {
+		if t.IsMinor() {
+			continue
+		}
+		size := axis.Tick.Label.Rectangle(t.Label).Size()
+		if size.X > width {
+			width = size.X
+		}
+		if size.Y > height {
+			height = size.Y
+		}
+	}
+	return width, height
+}
+
+// width of a y-axis drawn on the right of the data
+func rightAxisWidth(axis plot.Axis) vg.Length {
+	w := axis.Padding + axis.Width/2 + axis.Tick.Length
+	if width, _ := tickLabelSize(axis, axis.Tick.Marker.Ticks(axis.Min, axis.Max)); width > 0 {
+		w += width + axis.Tick.Label.Width(" ")
+	}
+	if axis.Label.Text != "" {
+		w += axis.Label.Padding + axis.Label.TextStyle.Height(axis.Label.Text)
+	}
+	return w
+}
+
+// height of an x-axis drawn on the top of the data
+func topAxisHeight(axis plot.Axis) vg.Length {
+	h := axis.Padding + axis.Width/2 + axis.Tick.Length
+	_, height := tickLabelSize(axis, axis.Tick.Marker.Ticks(axis.Min, axis.Max))
+	h += height
+	if axis.Label.Text != "" {
+		h += axis.Label.Padding + axis.Label.TextStyle.Height(axis.Label.Text)
+	}
+	return h
+}
+
+// y-axis on the right of the data canvas, mirroring the gonum left axis
+func drawRightAxis(dc draw.Canvas, axis plot.Axis) {
+	x := dc.Max.X + axis.Padding
+	dc.StrokeLine2(axis.LineStyle, x, dc.Min.Y, x, dc.Max.Y)
+	x += axis.Width / 2
+
+	ticks := axis.Tick.Marker.Ticks(axis.Min, axis.Max)
+	for _, t := range ticks {
+		y := dc.Y(axis.Norm(t.Value))
+		if !dc.ContainsY(y) {
+			continue
+		}
+		length := axis.Tick.Length
+		if t.IsMinor() {
+			length /= 2
+		}
+		dc.StrokeLine2(axis.Tick.LineStyle, x, y, x+length, y)
+	}
+	x += axis.Tick.Length + axis.Tick.Label.Width(" ")
+
+	sty := axis.Tick.Label
+	sty.XAlign, sty.YAlign = draw.XLeft, draw.YCenter
+	width, _ := tickLabelSize(axis, ticks)
+	for _, t := range ticks {
+		y := dc.Y(axis.Norm(t.Value))
+		if !dc.ContainsY(y) || t.IsMinor() {
+			continue
+		}
+		dc.FillText(sty, vg.Point{X: x, Y: y}, t.Label)
+	}
+	x += width
+
+	if axis.Label.Text != "" {
+		sty := axis.Label.TextStyle
+		sty.Rotation += math.Pi / 2
+		sty.XAlign, sty.YAlign = draw.XCenter, draw.YTop
+		dc.FillText(sty, vg.Point{X: x + axis.Label.Padding, Y: dc.Center().Y}, axis.Label.Text)
+	}
+}
+
+// x-axis on the top of the data canvas, mirroring the gonum bottom axis
+func drawTopAxis(dc draw.Canvas, axis plot.Axis) {
+	y := dc.Max.Y + axis.Padding
+	dc.StrokeLine2(axis.LineStyle, dc.Min.X, y, dc.Max.X, y)
+	y += axis.Width / 2
+
+	ticks := axis.Tick.Marker.Ticks(axis.Min, axis.Max)
+	for _, t := range ticks {
+		x := dc.X(axis.Norm(t.Value))
+		if !dc.ContainsX(x) {
+			continue
+		}
+		length := axis.Tick.Length
+		if t.IsMinor() {
+			length /= 2
+		}
+		dc.StrokeLine2(axis.Tick.LineStyle, x, y, x, y+length)
+	}
+	y += axis.Tick.Length
+
+	sty := axis.Tick.Label
+	sty.XAlign, sty.YAlign = draw.XCenter, draw.YBottom
+	_, height := tickLabelSize(axis, ticks)
+	for _, t := range ticks {
+		x := dc.X(axis.Norm(t.Value))
+		if !dc.ContainsX(x) || t.IsMinor() {
+			continue
+		}
+		dc.FillText(sty, vg.Point{X: x, Y: y}, t.Label)
+	}
+	y += height
+
+	if axis.Label.Text != "" {
+		sty := axis.Label.TextStyle
+		sty.XAlign, sty.YAlign = draw.XCenter, draw.YBottom
+		dc.FillText(sty, vg.Point{X: dc.Center().X, Y: y + axis.Label.Padding}, axis.Label.Text)
+	}
+}
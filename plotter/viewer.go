@@ -0,0 +1,598 @@
+package plotter
+
+import (
+	"image"
+	"image/color"
+	imgdraw "image/draw"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+const (
+	wheelZoom   = 1.2 // zoom factor of one step of the mouse wheel
+	snapPixels  = 8   // distance in pixels under which the mouse snaps to a point
+	clickPixels = 3   // distance in pixels under which a drag is a click
+)
+
+// data area and axis ranges of the last drawing of a plot
+type drawnView struct {
+	area vg.Rectangle
+	x, y [2]float64
+}
+
+// figure shown by a viewer, drawn at the size of its window
+type viewable interface {
+	image() image.Image
+	save(file string, options ...func(*saveOptions)) error
+	writeTo(w io.Writer, format formatType) (int64, error)
+	locker() sync.Locker
+	firstErr() error
+	isDirty() bool
+	pixelSize(width, height int) (restore func())
+	views() []*plotParameters
+	resolution() int
+	rasterSize() (xpixels, ypixels int)
+}
+
+// interactive view of a plot or a subplot, zoomed and panned by the events of the
+// window of Show or by events given to Handle, such as in tests without a display
+type Viewer struct {
+	figure    viewable
+	size      image.Point
+	image     image.Image
+	drawn     map[*plotParameters]drawnView // plots and twins in the image
+	home      []axesState                   // ranges of the plots before the first zoom or pan
+	drag      *dragState
+	hover     *hoverState
+	saveName  string
+	frameRate float64
+}
+
+type viewerOptions struct {
+	saveName  string
+	frameRate float64
+}
+
+// name without extension of the files saved from the viewer
+func WithSaveName(name string) func(*viewerOptions) {
+	return func(vo *viewerOptions) {
+		vo.saveName = name
+	}
+}
+
+// frames per second at which the window is drawn again
+// while the figure is changed from other goroutines
+func WithFrameRate(fps float64) func(*viewerOptions) {
+	return func(vo *viewerOptions) {
+		vo.frameRate = fps
+	}
+}
+
+func newViewer(figure viewable, options ...func(*viewerOptions)) *Viewer {
+	// default options
+	vo := viewerOptions{
+		saveName:  "figure",
+		frameRate: 30,
+	}
+
+	// apply additional options
+	for _, option := range options {
+		option(&vo)
+	}
+
+	v := &Viewer{figure: figure, saveName: vo.saveName, frameRate: vo.frameRate}
+	mu := figure.locker()
+	mu.Lock()
+	defer mu.Unlock()
+	if img := figure.image(); img != nil {
+		v.size = img.Bounds().Size()
+	}
+	return v
+}
+
+// events handled by the viewer, with positions in pixels from the top left corner
+type ViewerEvent interface {
+	viewerEvent()
+}
+
+// new size of the window in pixels
+type ResizeEvent struct {
+	Width, Height int
+}
+
+// turn of the mouse wheel, zooming out for a positive delta and in for a negative one
+type ScrollEvent struct {
+	X, Y  float64
+	Delta float64
+}
+
+// press of the mouse, starting a pan or, with Box, a box zoom
+type PressEvent struct {
+	X, Y float64
+	Box  bool
+}
+
+type DragEvent struct {
+	X, Y float64
+}
+
+// move of the mouse without a button pressed, showing the data under it
+type MoveEvent struct {
+	X, Y float64
+}
+
+// the mouse left the window
+type LeaveEvent struct{}
+
+// release of the mouse, ending a pan or zooming to the dragged box
+type ReleaseEvent struct {
+	X, Y float64
+}
+
+// key press: "h" or "home" resets the view, "s" saves it as PNG and shift+"s" as SVG
+type KeyEvent struct {
+	Name  string
+	Shift bool
+}
+
+func (ResizeEvent) viewerEvent()  {}
+func (ScrollEvent) viewerEvent()  {}
+func (PressEvent) viewerEvent()   {}
+func (DragEvent) viewerEvent()    {}
+func (MoveEvent) viewerEvent()    {}
+func (LeaveEvent) viewerEvent()   {}
+func (ReleaseEvent) viewerEvent() {}
+func (KeyEvent) viewerEvent()     {}
+
+// mouse drag in progress
+type dragState struct {
+	plot        *plotParameters
+	start, last vg.Point
+	box         bool
+}
+
+// data under the mouse shown by the viewer, at a point of a series or at the mouse
+type hoverState struct {
+	at    vg.Point
+	text  string
+	point bool
+}
+
+// point of a line or scatter series near the mouse
+type pickedPoint struct {
+	plot          *plotParameters
+	series, index int
+	at            vg.Point
+	x, y          float64
+}
+
+// axis ranges of a plot, restored by the home view
+type axesState struct {
+	plot             *plotParameters
+	x, y             [2]float64
+	xLimits, yLimits bool
+}
+
+// change the view with an event
+func (v *Viewer) Handle(e ViewerEvent) error {
+	mu := v.figure.locker()
+	mu.Lock()
+	picked, err := v.handle(e)
+	var pick func(series, index int)
+	if picked != nil {
+		pick = picked.plot.onPick
+	}
+	mu.Unlock()
+
+	// the pick is called without the lock, so that it may change the figure
+	if pick != nil {
+		pick(picked.series, picked.index)
+	}
+	return err
+}
+
+// change the view with an event while the figure is locked,
+// returns the clicked point when the plot has a pick
+func (v *Viewer) handle(e ViewerEvent) (*pickedPoint, error) {
+	switch e.(type) {
+	case ScrollEvent, PressEvent, DragEvent, ReleaseEvent, MoveEvent:
+		// positions are found on the image in the window
+		v.render()
+	}
+
+	switch e := e.(type) {
+	case ResizeEvent:
+		if e.Width > 0 && e.Height > 0 && (e.Width != v.size.X || e.Height != v.size.Y) {
+			v.size = image.Point{X: e.Width, Y: e.Height}
+			v.image = nil
+		}
+	case ScrollEvent:
+		at := v.point(e.X, e.Y)
+		if p := v.plotAt(at); p != nil {
+			tx, ty := v.drawn[p].fractions(at)
+			f := math.Pow(wheelZoom, e.Delta)
+			v.zoom(p, tx-tx*f, tx+(1-tx)*f, ty-ty*f, ty+(1-ty)*f)
+		}
+	case MoveEvent:
+		v.hover = v.hoverAt(v.point(e.X, e.Y))
+	case LeaveEvent:
+		v.hover = nil
+	case PressEvent:
+		at := v.point(e.X, e.Y)
+		v.hover = nil
+		v.drag = nil
+		if p := v.plotAt(at); p != nil {
+			v.drag = &dragState{plot: p, start: at, last: at, box: e.Box}
+		}
+	case DragEvent:
+		if v.drag == nil {
+			return nil, nil
+		}
+		at := v.point(e.X, e.Y)
+		if !v.drag.box {
+			x0, y0 := v.drawn[v.drag.plot].fractions(v.drag.last)
+			x1, y1 := v.drawn[v.drag.plot].fractions(at)
+			v.zoom(v.drag.plot, x0-x1, 1+x0-x1, y0-y1, 1+y0-y1)
+		}
+		v.drag.last = at
+		v.image = nil
+	case ReleaseEvent:
+		d := v.drag
+		v.drag = nil
+		v.image = nil
+		if d == nil {
+			return nil, nil
+		}
+
+		// drags of a few pixels are clicks, picking the point under the mouse
+		at := v.point(e.X, e.Y)
+		if dx, dy := v.pixels(at.Sub(d.start)); math.Abs(dx) < clickPixels && math.Abs(dy) < clickPixels {
+			if p := v.nearest(at); p != nil && p.plot.onPick != nil {
+				return p, nil
+			}
+			return nil, nil
+		}
+		if !d.box {
+			return nil, nil
+		}
+		x0, y0 := v.drawn[d.plot].fractions(d.start)
+		x1, y1 := v.drawn[d.plot].fractions(at)
+		v.zoom(d.plot, math.Min(x0, x1), math.Max(x0, x1), math.Min(y0, y1), math.Max(y0, y1))
+	case KeyEvent:
+		switch strings.ToLower(e.Name) {
+		case "h", "home":
+			v.reset()
+		case "s":
+			if e.Shift {
+				return nil, v.save(v.saveName + ".svg")
+			}
+			return nil, v.save(v.saveName + ".png")
+		}
+	}
+	return nil, nil
+}
+
+// figure drawn at the size of the viewer, with the box of a box zoom in progress
+// and the data under the mouse
+func (v *Viewer) Image() image.Image {
+	// the drag and hover state written by Handle is read under the lock,
+	// the drawn figure is a new image for each render and is not changed after
+	mu := v.figure.locker()
+	mu.Lock()
+	defer mu.Unlock()
+	v.render()
+
+	boxing := v.drag != nil && v.drag.box
+	if v.image == nil || !boxing && v.hover == nil {
+		return v.image
+	}
+
+	// transparent canvas drawn over the figure
+	dpi := v.figure.resolution()
+	scale := vg.Inch / vg.Length(dpi)
+	c := vgimg.NewWith(
+		vgimg.UseWH(vg.Length(v.size.X)*scale, vg.Length(v.size.Y)*scale),
+		vgimg.UseDPI(dpi),
+		vgimg.UseBackgroundColor(color.Transparent),
+	)
+	if boxing {
+		c.SetColor(color.Black)
+		c.SetLineWidth(vg.Points(0.5))
+		c.Stroke(vg.Rectangle{Min: v.drag.start, Max: v.drag.last}.Path())
+	}
+	if v.hover != nil {
+		v.hover.draw(c, vg.Point{X: vg.Length(v.size.X) * scale, Y: vg.Length(v.size.Y) * scale})
+	}
+
+	img := image.NewRGBA(v.image.Bounds())
+	imgdraw.Draw(img, img.Bounds(), v.image, image.Point{}, imgdraw.Src)
+	imgdraw.Draw(img, img.Bounds(), c.Image(), image.Point{}, imgdraw.Over)
+	return img
+}
+
+// data under the mouse, the nearest point of a series or the coordinates of the mouse
+func (v *Viewer) hoverAt(at vg.Point) *hoverState {
+	if p := v.nearest(at); p != nil {
+		text := formatXY(p.x, p.y)
+		if label := p.plot.series[p.series].label; label != "" {
+			text = label + ": " + text
+		}
+		return &hoverState{at: p.at, text: text, point: true}
+	}
+
+	p := v.plotAt(at)
+	if p == nil {
+		return nil
+	}
+	dv := v.drawn[p]
+	tx, ty := dv.fractions(at)
+	x := zoomRange(p.xScale, dv.x, tx, tx)[0]
+	y := zoomRange(p.yScale, dv.y, ty, ty)[0]
+	return &hoverState{at: at, text: formatXY(x, y)}
+}
+
+func formatXY(x, y float64) string {
+	return "x=" + strconv.FormatFloat(x, 'g', 4, 64) + ", y=" + strconv.FormatFloat(y, 'g', 4, 64)
+}
+
+// draw a ring around the point and the text in a box next to it,
+// inside a canvas of the given size
+func (h *hoverState) draw(c vg.Canvas, size vg.Point) {
+	text := plot.NewLegend().TextStyle
+	text.XAlign, text.YAlign = draw.XLeft, draw.YBottom
+	em := text.Width("M")
+
+	if h.point {
+		var ring vg.Path
+		ring.Arc(h.at, em/2, 0, 2*math.Pi)
+		ring.Close()
+		c.SetColor(color.Black)
+		c.SetLineWidth(vg.Points(1))
+		c.Stroke(ring)
+	}
+
+	// the box goes on the upper right of the point, or on its left or below near the edges
+	boxSize := vg.Point{X: text.Width(h.text) + em, Y: text.Height(h.text) + em/2}
+	at := h.at.Add(vg.Point{X: em, Y: em})
+	if at.X+boxSize.X > size.X {
+		at.X = h.at.X - em - boxSize.X
+	}
+	if at.Y+boxSize.Y > size.Y {
+		at.Y = h.at.Y - em - boxSize.Y
+	}
+	box := vg.Rectangle{Min: at, Max: at.Add(boxSize)}
+	c.SetColor(color.White)
+	c.Fill(box.Path())
+	c.SetColor(color.Black)
+	c.SetLineWidth(vg.Points(0.5))
+	c.Stroke(box.Path())
+	dc := draw.Canvas{Canvas: c, Rectangle: box}
+	dc.FillText(text, at.Add(vg.Point{X: em / 2, Y: em / 4}), h.text)
+}
+
+// draw the figure again at the size of the viewer after a change, with the figure locked
+func (v *Viewer) render() {
+	if v.image != nil && !v.figure.isDirty() || v.size.X <= 0 || v.size.Y <= 0 {
+		return
+	}
+	restore := v.figure.pixelSize(v.size.X, v.size.Y)
+	v.image = v.figure.image()
+	restore()
+
+	v.drawn = make(map[*plotParameters]drawnView)
+	for _, view := range v.figure.views() {
+		for _, p := range view.withTwins() {
+			v.drawn[p] = p.view
+		}
+	}
+}
+
+// reset the plots to the ranges they had before the first zoom or pan
+func (v *Viewer) Home() {
+	mu := v.figure.locker()
+	mu.Lock()
+	defer mu.Unlock()
+	v.reset()
+}
+
+func (v *Viewer) reset() {
+	for _, s := range v.home {
+		s.plot.dirty = true
+		s.plot.plot.X.Min, s.plot.plot.X.Max = s.x[0], s.x[1]
+		s.plot.plot.Y.Min, s.plot.plot.Y.Max = s.y[0], s.y[1]
+		s.plot.xLimits, s.plot.yLimits = s.xLimits, s.yLimits
+	}
+	v.home = nil
+	v.image = nil
+}
+
+// save the current view at the size of the viewer, in the format given by the extension
+func (v *Viewer) Save(file string, options ...func(*saveOptions)) error {
+	mu := v.figure.locker()
+	mu.Lock()
+	defer mu.Unlock()
+	return v.save(file, options...)
+}
+
+func (v *Viewer) save(file string, options ...func(*saveOptions)) error {
+	defer v.figure.pixelSize(v.size.X, v.size.Y)()
+	return v.figure.save(file, options...)
+}
+
+// the figure was changed since the viewer last drew it
+func (v *Viewer) changed() bool {
+	mu := v.figure.locker()
+	mu.Lock()
+	defer mu.Unlock()
+	return v.figure.isDirty()
+}
+
+// interval between two frames of the window
+func (v *Viewer) frameInterval() time.Duration {
+	if v.frameRate <= 0 {
+		return time.Second / 30
+	}
+	return time.Duration(float64(time.Second) / v.frameRate)
+}
+
+// point of the figure at a pixel of the window
+func (v *Viewer) point(x, y float64) vg.Point {
+	scale := vg.Inch / vg.Length(v.figure.resolution())
+	return vg.Point{X: vg.Length(x) * scale, Y: vg.Length(float64(v.size.Y)-y) * scale}
+}
+
+// size in pixels of a distance on the figure
+func (v *Viewer) pixels(d vg.Point) (x, y float64) {
+	scale := float64(v.figure.resolution()) / float64(vg.Inch)
+	return float64(d.X) * scale, float64(d.Y) * scale
+}
+
+// plot whose data area contains the point, nil if none
+func (v *Viewer) plotAt(pt vg.Point) *plotParameters {
+	for _, p := range v.figure.views() {
+		if dv, ok := v.drawn[p]; ok && dv.contains(pt) {
+			return p
+		}
+	}
+	return nil
+}
+
+// call pick with the series, in the order it was plotted, and the index
+// of the point clicked in the viewer
+func (plt *plotParameters) OnPick(pick func(series, index int)) {
+	defer plt.lock()()
+	plt.onPick = pick
+}
+
+// nearest point of the shown line and scatter series to the mouse, nil if none is near
+func (v *Viewer) nearest(at vg.Point) *pickedPoint {
+	var nearest *pickedPoint
+	best := math.Inf(1)
+	for _, view := range v.figure.views() {
+		for _, p := range view.withTwins() {
+			dv, ok := v.drawn[p]
+			if !ok {
+				continue
+			}
+			for i, s := range p.series {
+				xys := pickable(s)
+				if xys == nil || s.hidden {
+					continue
+				}
+				for j := 0; j < xys.Len(); j++ {
+					x, y := xys.XY(j)
+					pt := dv.at(p.xScale, p.yScale, x, y)
+					if !dv.contains(pt) {
+						continue
+					}
+					if dist := math.Hypot(v.pixels(pt.Sub(at))); dist < snapPixels && dist < best {
+						best = dist
+						nearest = &pickedPoint{plot: p, series: i, index: j, at: pt, x: x, y: y}
+					}
+				}
+			}
+		}
+	}
+	return nearest
+}
+
+// points of a line or scatter series, nil for the other series
+func pickable(s *series) plotter.XYer {
+	for _, pl := range s.plotters {
+		switch pl := pl.(type) {
+		case *plotter.Line:
+			return pl.XYs
+		case *plotter.Scatter:
+			return pl.XYs
+		}
+	}
+	return nil
+}
+
+// zoom a plot and its twins to the fractions [x0, x1] and [y0, y1] of their drawn ranges
+func (v *Viewer) zoom(p *plotParameters, x0, x1, y0, y1 float64) {
+	if v.home == nil {
+		for _, view := range v.figure.views() {
+			for _, p := range view.withTwins() {
+				v.home = append(v.home, axesState{
+					plot:    p,
+					x:       [2]float64{p.plot.X.Min, p.plot.X.Max},
+					y:       [2]float64{p.plot.Y.Min, p.plot.Y.Max},
+					xLimits: p.xLimits,
+					yLimits: p.yLimits,
+				})
+			}
+		}
+	}
+	v.image = nil
+
+	v.zoomX(p, x0, x1)
+	v.zoomY(p, y0, y1)
+	if twin := p.twinX; twin != nil {
+		v.zoomY(twin, y0, y1)
+	}
+	if twin := p.twinY; twin != nil {
+		v.zoomX(twin, x0, x1)
+	}
+}
+
+// point of the data area at the data coordinates x and y
+func (dv drawnView) at(xScale, yScale scaleType, x, y float64) vg.Point {
+	norm := func(scale scaleType, r [2]float64, v float64) vg.Length {
+		u, w := scale.forward(r[0]), scale.forward(r[1])
+		return vg.Length((scale.forward(v) - u) / (w - u))
+	}
+	size := dv.area.Size()
+	return vg.Point{
+		X: dv.area.Min.X + norm(xScale, dv.x, x)*size.X,
+		Y: dv.area.Min.Y + norm(yScale, dv.y, y)*size.Y,
+	}
+}
+
+func (dv drawnView) contains(pt vg.Point) bool {
+	a := dv.area
+	return pt.X >= a.Min.X && pt.X <= a.Max.X && pt.Y >= a.Min.Y && pt.Y <= a.Max.Y
+}
+
+// fractions of the data area at the point, from its lower left corner
+func (dv drawnView) fractions(pt vg.Point) (x, y float64) {
+	size := dv.area.Size()
+	return float64((pt.X - dv.area.Min.X) / size.X), float64((pt.Y - dv.area.Min.Y) / size.Y)
+}
+
+// x-axis limits of a plot at the fractions t0 and t1 of the drawn range, along the scale of the axis
+func (v *Viewer) zoomX(p *plotParameters, t0, t1 float64) {
+	p.dirty = true
+
+	dv := v.drawn[p]
+	dv.x = zoomRange(p.xScale, dv.x, t0, t1)
+	v.drawn[p] = dv
+	p.plot.X.Min, p.plot.X.Max = dv.x[0], dv.x[1]
+	p.xLimits = true
+}
+
+// y-axis limits of a plot at the fractions t0 and t1 of the drawn range, along the scale of the axis
+func (v *Viewer) zoomY(p *plotParameters, t0, t1 float64) {
+	p.dirty = true
+
+	dv := v.drawn[p]
+	dv.y = zoomRange(p.yScale, dv.y, t0, t1)
+	v.drawn[p] = dv
+	p.plot.Y.Min, p.plot.Y.Max = dv.y[0], dv.y[1]
+	p.yLimits = true
+}
+
+func zoomRange(scale scaleType, drawn [2]float64, t0, t1 float64) [2]float64 {
+	u, w := scale.forward(drawn[0]), scale.forward(drawn[1])
+	return [2]float64{scale.inverse(u + t0*(w-u)), scale.inverse(u + t1*(w-u))}
+}
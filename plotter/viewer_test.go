@@ -0,0 +1,196 @@
+package plotter
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+// viewer of a line through three points with the ranges [0, 10], drawn at 400x400 px
+func newTestViewer(t *testing.T) (*plotParameters, *Viewer) {
+	t.Helper()
+	p := NewPlot().(*plotParameters)
+	p.Plot([]float64{2, 5, 8}, []float64{2, 5, 8})
+	p.XLim(0, 10)
+	p.YLim(0, 10)
+
+	v := p.Viewer()
+	if err := v.Handle(ResizeEvent{Width: 400, Height: 400}); err != nil {
+		t.Fatal(err)
+	}
+	if v.Image() == nil {
+		t.Fatal("viewer not drawn")
+	}
+	return p, v
+}
+
+// pixel of the window at the data coordinates of the plot, in the view drawn after the last event
+func pixelAt(v *Viewer, p *plotParameters, x, y float64) (float64, float64) {
+	mu := p.locker()
+	mu.Lock()
+	defer mu.Unlock()
+	v.render()
+	px, py := v.pixels(v.drawn[p].at(p.xScale, p.yScale, x, y))
+	return px, float64(v.size.Y) - py
+}
+
+func handle(t *testing.T, v *Viewer, events ...ViewerEvent) {
+	t.Helper()
+	for _, e := range events {
+		if err := v.Handle(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func checkRanges(t *testing.T, p *plotParameters, x, y [2]float64) {
+	t.Helper()
+	const tol = 1e-6
+	got := [4]float64{p.plot.X.Min, p.plot.X.Max, p.plot.Y.Min, p.plot.Y.Max}
+	want := [4]float64{x[0], x[1], y[0], y[1]}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > tol {
+			t.Fatalf("ranges x=[%g, %g] y=[%g, %g], want x=[%g, %g] y=[%g, %g]",
+				got[0], got[1], got[2], got[3], want[0], want[1], want[2], want[3])
+		}
+	}
+}
+
+func TestViewerScrollZoom(t *testing.T) {
+	p, v := newTestViewer(t)
+	x, y := pixelAt(v, p, 5, 5)
+
+	handle(t, v, ScrollEvent{X: x, Y: y, Delta: -1})
+	half := 5 / wheelZoom
+	checkRanges(t, p, [2]float64{5 - half, 5 + half}, [2]float64{5 - half, 5 + half})
+
+	x, y = pixelAt(v, p, 5, 5)
+	handle(t, v, ScrollEvent{X: x, Y: y, Delta: 1})
+	checkRanges(t, p, [2]float64{0, 10}, [2]float64{0, 10})
+}
+
+func TestViewerScrollOutsideData(t *testing.T) {
+	p, v := newTestViewer(t)
+
+	handle(t, v, ScrollEvent{X: 1, Y: 1, Delta: -1})
+	checkRanges(t, p, [2]float64{0, 10}, [2]float64{0, 10})
+}
+
+func TestViewerPan(t *testing.T) {
+	p, v := newTestViewer(t)
+	x0, y0 := pixelAt(v, p, 5, 5)
+	x1, y1 := pixelAt(v, p, 7, 4)
+
+	handle(t, v, PressEvent{X: x0, Y: y0}, DragEvent{X: x1, Y: y1}, ReleaseEvent{X: x1, Y: y1})
+	checkRanges(t, p, [2]float64{-2, 8}, [2]float64{1, 11})
+}
+
+func TestViewerBoxZoom(t *testing.T) {
+	p, v := newTestViewer(t)
+	x0, y0 := pixelAt(v, p, 6, 8)
+	x1, y1 := pixelAt(v, p, 2, 3)
+
+	handle(t, v, PressEvent{X: x0, Y: y0, Box: true}, DragEvent{X: x1, Y: y1}, ReleaseEvent{X: x1, Y: y1})
+	checkRanges(t, p, [2]float64{2, 6}, [2]float64{3, 8})
+}
+
+func TestViewerHome(t *testing.T) {
+	for _, key := range []KeyEvent{{Name: "home"}, {Name: "h"}, {Name: "H"}} {
+		p, v := newTestViewer(t)
+		x, y := pixelAt(v, p, 5, 5)
+
+		handle(t, v, ScrollEvent{X: x, Y: y, Delta: -1}, ScrollEvent{X: x, Y: y, Delta: -1})
+		x1, y1 := pixelAt(v, p, 6, 6)
+		handle(t, v, PressEvent{X: x, Y: y}, DragEvent{X: x1, Y: y1}, ReleaseEvent{X: x1, Y: y1})
+		handle(t, v, key)
+		checkRanges(t, p, [2]float64{0, 10}, [2]float64{0, 10})
+		if !p.xLimits || !p.yLimits {
+			t.Errorf("key %q: limits of XLim and YLim not restored", key.Name)
+		}
+	}
+}
+
+func TestViewerHomeAutomaticRanges(t *testing.T) {
+	p := NewPlot().(*plotParameters)
+	p.Plot([]float64{2, 5, 8}, []float64{2, 5, 8})
+	v := p.Viewer()
+	handle(t, v, ResizeEvent{Width: 400, Height: 400})
+	v.Image()
+	x, y := pixelAt(v, p, 5, 5)
+
+	handle(t, v, ScrollEvent{X: x, Y: y, Delta: -1})
+	if !p.xLimits {
+		t.Fatal("zoom did not fix the x-axis limits")
+	}
+	v.Home()
+	if p.xLimits || p.yLimits {
+		t.Error("home kept the limits of the zoom")
+	}
+}
+
+func TestViewerPickSnapping(t *testing.T) {
+	p, v := newTestViewer(t)
+	var picks [][2]int
+	p.OnPick(func(series, index int) {
+		picks = append(picks, [2]int{series, index})
+	})
+
+	// a click a few pixels away from the middle point snaps to it
+	x, y := pixelAt(v, p, 5, 5)
+	handle(t, v, PressEvent{X: x + 4, Y: y - 3}, ReleaseEvent{X: x + 4, Y: y - 3})
+	if len(picks) != 1 || picks[0] != [2]int{0, 1} {
+		t.Fatalf("picks %v, want [[0 1]]", picks)
+	}
+
+	// a click farther than the snap distance picks nothing
+	handle(t, v, PressEvent{X: x + 2*snapPixels, Y: y}, ReleaseEvent{X: x + 2*snapPixels, Y: y})
+	if len(picks) != 1 {
+		t.Fatalf("picks %v after a click away from the points", picks)
+	}
+
+	// a drag of less than the click distance is still a click
+	x, y = pixelAt(v, p, 8, 8)
+	handle(t, v, PressEvent{X: x, Y: y}, DragEvent{X: x + 1, Y: y + 1}, ReleaseEvent{X: x + 1, Y: y + 1})
+	if len(picks) != 2 || picks[1] != [2]int{0, 2} {
+		t.Fatalf("picks %v, want [[0 1] [0 2]]", picks)
+	}
+}
+
+func TestViewerHover(t *testing.T) {
+	p, v := newTestViewer(t)
+	x, y := pixelAt(v, p, 2, 2)
+
+	handle(t, v, MoveEvent{X: x + 3, Y: y})
+	if v.hover == nil || !v.hover.point || v.hover.text != "x=2, y=2" {
+		t.Fatalf("hover %+v, want the point x=2, y=2", v.hover)
+	}
+	handle(t, v, LeaveEvent{})
+	if v.hover != nil {
+		t.Fatal("hover kept after the mouse left")
+	}
+}
+
+// run with -race, the window loop draws the image while the events are handled on another goroutine
+func TestViewerConcurrentHandleImage(t *testing.T) {
+	p, v := newTestViewer(t)
+	x, y := pixelAt(v, p, 5, 5)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			v.Image()
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		dx := float64(i % 10)
+		handle(t, v,
+			MoveEvent{X: x + dx, Y: y},
+			PressEvent{X: x, Y: y, Box: true},
+			DragEvent{X: x + 20 + dx, Y: y + 20},
+			ReleaseEvent{X: x + 1, Y: y},
+			LeaveEvent{})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,194 @@
+package plotter
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+type violinOptions struct {
+	width     float64
+	color     color.Color
+	bandwidth float64
+	points    int
+	label     string
+}
+
+// name of the violin plot in the legend
+func WithViolinLabel(label string) func(*violinOptions) {
+	return func(vo *violinOptions) {
+		vo.label = label
+	}
+}
+
+// violin width as a fraction of the space between categories
+func WithViolinWidth(width float64) func(*violinOptions) {
+	return func(vo *violinOptions) {
+		vo.width = width
+	}
+}
+
+func WithViolinColor(color colorType) func(*violinOptions) {
+	return func(vo *violinOptions) {
+		vo.color = color
+	}
+}
+
+// bandwidth of the gaussian kernel, by default given by the Scott rule
+func WithBandwidth(bandwidth float64) func(*violinOptions) {
+	return func(vo *violinOptions) {
+		vo.bandwidth = bandwidth
+	}
+}
+
+// number of points where the density is evaluated
+func WithViolinPoints(points int) func(*violinOptions) {
+	return func(vo *violinOptions) {
+		vo.points = points
+	}
+}
+
+// parameters to violin plot
+func (plt *plotParameters) Violin(groups [][]float64, labels []string, options ...func(*violinOptions)) {
+	defer plt.lock()()
+	plt.dirty = true
+
+	// default options
+	vo := violinOptions{
+		width:  0.8,
+		points: 100,
+	}
+
+	// apply additional options
+	for _, option := range options {
+		option(&vo)
+	}
+
+	labels, err := checkGroups(groups, labels)
+	if err != nil {
+		plt.setErr(err)
+		return
+	}
+	if vo.bandwidth < 0 || vo.points < 2 {
+		plt.setErr(fmt.Errorf("plotter: invalid violin bandwidth %g or points %d", vo.bandwidth, vo.points))
+		return
+	}
+
+	v := &violinPlot{
+		width: vo.width,
+		line:  draw.LineStyle{Color: Black, Width: vg.Points(1)},
+	}
+	for _, group := range groups {
+		values, density := kde(group, vo.bandwidth, vo.points)
+		v.values = append(v.values, values)
+		v.densities = append(v.densities, density)
+		v.stats = append(v.stats, newBoxStats(group, math.Inf(1), [2]float64{}))
+	}
+
+	// automatic color assignment
+	if vo.color == nil {
+		vo.color = plt.nextColor()
+	} else {
+		plt.lineOptions.useColor(vo.color)
+	}
+	v.fill = withAlpha(vo.color, 0.5)
+
+	// add the plotters to the plot
+	plt.add(vo.label, []plot.Thumbnailer{v}, v)
+	plt.categoryAxis(labels, false)
+}
+
+// gaussian kernel density estimate of the data between its minimum and maximum
+func kde(data []float64, bandwidth float64, points int) (values, density []float64) {
+	n := float64(len(data))
+	if bandwidth == 0 {
+		// Scott rule
+		bandwidth = stat.StdDev(data, nil) * math.Pow(n, -1./5)
+	}
+
+	min, max := floats.Min(data), floats.Max(data)
+	if bandwidth == 0 || math.IsNaN(bandwidth) {
+		// all data are equal, a small arbitrary spread
+		bandwidth = math.Max(math.Abs(min)*1e-3, 1e-3)
+	}
+
+	values = Linspace(min, max, points)
+	density = make([]float64, points)
+	norm := 1 / (n * bandwidth * math.Sqrt(2*math.Pi))
+	for i, x := range values {
+		for _, d := range data {
+			u := (x - d) / bandwidth
+			density[i] += math.Exp(-u * u / 2)
+		}
+		density[i] *= norm
+	}
+	return values, density
+}
+
+// plotter that draws the density of each category as a violin
+// with its interquartile range and median inside
+type violinPlot struct {
+	values    [][]float64
+	densities [][]float64
+	stats     []boxStats
+	width     float64
+	fill      color.Color
+	line      draw.LineStyle
+}
+
+func (v *violinPlot) Plot(c draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&c)
+
+	for i, values := range v.values {
+		cat := float64(i)
+		half := v.width / 2 / floats.Max(v.densities[i])
+
+		// outline of both sides of the density
+		pts := make([]vg.Point, 0, 2*len(values))
+		for j, y := range values {
+			pts = append(pts, vg.Point{X: trX(cat - v.densities[i][j]*half), Y: trY(y)})
+		}
+		for j := len(values) - 1; j >= 0; j-- {
+			pts = append(pts, vg.Point{X: trX(cat + v.densities[i][j]*half), Y: trY(values[j])})
+		}
+		c.FillPolygon(v.fill, c.ClipPolygonXY(pts))
+		c.StrokeLines(v.line, c.ClipLinesXY(append(pts, pts[0]))...)
+
+		// interquartile range as a thick line and median as a white dot
+		s := v.stats[i]
+		mid := trX(cat)
+		iqr := v.line
+		iqr.Width *= 4
+		c.StrokeLines(iqr, c.ClipLinesY([]vg.Point{{X: mid, Y: trY(s.q1)}, {X: mid, Y: trY(s.q3)}})...)
+		med := vg.Point{X: mid, Y: trY(s.median)}
+		if c.Contains(med) {
+			c.DrawGlyph(draw.GlyphStyle{Color: color.White, Radius: iqr.Width / 2, Shape: Circle}, med)
+		}
+	}
+}
+
+func (v *violinPlot) DataRange() (xmin, xmax, ymin, ymax float64) {
+	ymin, ymax = math.Inf(1), math.Inf(-1)
+	for _, values := range v.values {
+		ymin = math.Min(ymin, values[0])
+		ymax = math.Max(ymax, values[len(values)-1])
+	}
+	return -0.5, float64(len(v.values)) - 0.5, ymin, ymax
+}
+
+func (v *violinPlot) Thumbnail(c *draw.Canvas) {
+	pts := []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y},
+		{X: c.Min.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Min.Y},
+	}
+	c.FillPolygon(v.fill, c.ClipPolygonY(pts))
+	c.StrokeLines(v.line, c.ClipLinesY(append(pts, pts[0]))...)
+}
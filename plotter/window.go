@@ -0,0 +1,225 @@
+package plotter
+
+import (
+	"image"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+// graphical window of a figure opened by ShowAsync
+type Window struct {
+	window *app.Window
+	done   chan struct{}
+	err    error
+}
+
+// figures queued by ShowLater and not shown yet, opened by ShowAll
+var pending struct {
+	sync.Mutex
+	figures []viewable
+}
+
+func addPending(figure viewable) {
+	pending.Lock()
+	defer pending.Unlock()
+	for _, f := range pending.figures {
+		if f == figure {
+			return
+		}
+	}
+	pending.figures = append(pending.figures, figure)
+}
+
+func removePending(figure viewable) {
+	pending.Lock()
+	defer pending.Unlock()
+	for i, f := range pending.figures {
+		if f == figure {
+			pending.figures = append(pending.figures[:i], pending.figures[i+1:]...)
+			return
+		}
+	}
+}
+
+// run the program with the windows of its figures, for the systems such as macOS
+// where the windows must run on the main thread: Main is called from the main
+// function, run runs in its own goroutine while the main goroutine runs the windows,
+// and the program exits when run returns
+func Main(run func()) {
+	go func() {
+		run()
+		os.Exit(0)
+	}()
+	app.Main()
+}
+
+// show every plot and subplot queued by ShowLater, each in its own window,
+// and wait until all the windows are closed, inside Main on macOS
+func ShowAll(options ...func(*viewerOptions)) error {
+	pending.Lock()
+	figures := pending.figures
+	pending.figures = nil
+	pending.Unlock()
+
+	windows := make([]*Window, len(figures))
+	for i, figure := range figures {
+		windows[i] = showAsync(figure, options...)
+	}
+
+	var err error
+	for _, w := range windows {
+		if werr := w.Wait(); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// open a window showing the figure without waiting for it to be closed,
+// the event loop of the window runs in its own goroutine and, on macOS,
+// the window is only opened once Main runs on the main goroutine
+func showAsync(figure viewable, options ...func(*viewerOptions)) *Window {
+	removePending(figure)
+
+	w := &Window{done: make(chan struct{})}
+	mu := figure.locker()
+	mu.Lock()
+	w.err = figure.firstErr()
+	mu.Unlock()
+	if w.err != nil {
+		close(w.done)
+		return w
+	}
+
+	v := newViewer(figure, options...)
+	w.window = app.NewWindow(
+		app.Title("Plot Viewer"),
+		app.Size(unit.Dp(float32(v.size.X)), unit.Dp(float32(v.size.Y))),
+	)
+	go func() {
+		defer close(w.done)
+		w.err = v.run(w.window)
+	}()
+	return w
+}
+
+// close the window, Wait returns once it is destroyed
+func (w *Window) Close() {
+	if w.window != nil {
+		w.window.Close()
+	}
+}
+
+// wait until the window is closed, returns the error that closed it
+// or the first error of the figure or of saving its view
+func (w *Window) Wait() error {
+	<-w.done
+	return w.err
+}
+
+// handle the events of the window until it is closed, drawing the viewer at the size of the window
+func (v *Viewer) run(window *app.Window) error {
+	// draw the window again at the frame rate while the figure changes
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(v.frameInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if v.changed() {
+					window.Invalidate()
+				}
+			}
+		}
+	}()
+
+	var ops op.Ops
+	var err error
+	for e := range window.Events() {
+		switch e := e.(type) {
+		case system.DestroyEvent:
+			if e.Err != nil {
+				return e.Err
+			}
+			return err
+		case system.FrameEvent:
+			gtx := layout.NewContext(&ops, e)
+			v.Handle(ResizeEvent{Width: e.Size.X, Height: e.Size.Y})
+			for _, e := range gtx.Events(v) {
+				if ve := windowEvent(e); ve != nil {
+					if herr := v.Handle(ve); herr != nil && err == nil {
+						err = herr
+					}
+				}
+			}
+
+			// mouse and keyboard input over the whole window
+			pointer.Rect(image.Rectangle{Max: e.Size}).Add(gtx.Ops)
+			pointer.InputOp{
+				Tag: v,
+				Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel |
+					pointer.Scroll | pointer.Move | pointer.Leave,
+			}.Add(gtx.Ops)
+			key.InputOp{Tag: v}.Add(gtx.Ops)
+			key.FocusOp{Tag: v}.Add(gtx.Ops)
+
+			if img := v.Image(); img != nil {
+				paint.NewImageOp(img).Add(gtx.Ops)
+				paint.PaintOp{}.Add(gtx.Ops)
+			}
+			e.Frame(gtx.Ops)
+		}
+	}
+	return err
+}
+
+// viewer event of a window event, nil for the events the viewer ignores
+func windowEvent(e interface{}) ViewerEvent {
+	switch e := e.(type) {
+	case pointer.Event:
+		x, y := float64(e.Position.X), float64(e.Position.Y)
+		switch e.Type {
+		case pointer.Press:
+			box := e.Buttons == pointer.ButtonSecondary || e.Modifiers.Contain(key.ModShift)
+			return PressEvent{X: x, Y: y, Box: box}
+		case pointer.Drag:
+			return DragEvent{X: x, Y: y}
+		case pointer.Move:
+			return MoveEvent{X: x, Y: y}
+		case pointer.Leave:
+			return LeaveEvent{}
+		case pointer.Release, pointer.Cancel:
+			return ReleaseEvent{X: x, Y: y}
+		case pointer.Scroll:
+			if e.Scroll.Y == 0 {
+				return nil
+			}
+			return ScrollEvent{X: x, Y: y, Delta: math.Copysign(1, float64(e.Scroll.Y))}
+		}
+	case key.Event:
+		if e.State != key.Press {
+			return nil
+		}
+		name := e.Name
+		if name == key.NameHome {
+			name = "home"
+		}
+		return KeyEvent{Name: name, Shift: e.Modifiers.Contain(key.ModShift)}
+	}
+	return nil
+}